@@ -1,55 +1,186 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/simplyzetax/oracle/internal/ai"
+	"github.com/simplyzetax/oracle/internal/attach"
 	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/provider"
 	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/pkg/types"
 	"github.com/spf13/cobra"
 )
 
+var (
+	continueSession bool
+	sessionID       string
+	attachFiles     []string
+	attachDirs      []string
+	attachImages    []string
+	tokenBudget     int
+	agentMode       bool
+	maxSteps        int
+	templateName    string
+	templateVars    []string
+	noCache         bool
+	refreshCache    bool
+	outputMode      string
+)
+
 var askCmd = &cobra.Command{
 	Use:   "ask [question]",
 	Short: "Ask a question to the AI model",
 	Long: `Ask a question to the AI model and get a streaming response.
 	
 The question can be provided as arguments or you'll be prompted to enter it interactively.
+When input is piped in, it is attached to the question as context.
 
 Examples:
   oracle ask "What is the meaning of life?"
   oracle ask "Explain quantum computing in simple terms"
+  oracle ask --continue "And how does that compare to classical bits?"
+  kubectl logs my-pod | oracle ask "Why is this crashing?"
+  oracle ask "Why won't this start?" -f docker-compose.yml -f '.env.*'
+  oracle ask "Review this package" --dir internal/config
+  oracle ask "What does this error dialog mean?" --image screenshot.png
+  oracle ask --agent "Find out why the nginx container keeps restarting"
+  oracle ask --template k8s-debug --var ns=prod
+  oracle ask --refresh "What's the latest stable Go release?"
+  oracle ask --output json "Which port does postgres listen on?" | jq -r .answer
   oracle ask`,
-	Run: func(cmd *cobra.Command, args []string) {
-		// Check for API key and prompt if needed
-		if err := checkAndSetupAPIKey(); err != nil {
-			ui.ShowError("Failed to setup API key: " + err.Error())
-			return
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := ui.SetOutput(outputMode); err != nil {
+			return err
+		}
+
+		if err := setupAPIKeyIfNeeded(); err != nil {
+			return fmt.Errorf("failed to set up API key: %w", err)
+		}
+
+		opts := aiOptions()
+		opts.Continue = continueSession
+		opts.SessionID = sessionID
+
+		question := strings.Join(args, " ")
+
+		// A template becomes the question, with any arguments added as extra detail
+		if templateName != "" {
+			rendered, err := renderTemplate(templateName, templateVars)
+			if err != nil {
+				return err
+			}
+			if question != "" {
+				rendered += "\n\n" + question
+			}
+			question = rendered
 		}
 
-		var question string
+		if len(attachFiles) > 0 || len(attachDirs) > 0 {
+			files, skipped, err := attach.Collect(attachFiles, attachDirs)
+			if err != nil {
+				return err
+			}
+			for _, s := range skipped {
+				ui.ShowExecutionStatus(fmt.Sprintf("Skipped %s: %s", s.Path, s.Reason), "warning")
+			}
+			opts.Attachments = append(opts.Attachments, files...)
+		}
+		opts.TokenBudget = tokenBudget
+		opts.Agent = agentMode
+		opts.MaxSteps = maxSteps
+		opts.NoCache = noCache
+		opts.RefreshCache = refreshCache
+
+		if len(attachImages) > 0 {
+			images, err := attach.LoadImages(attachImages)
+			if err != nil {
+				return err
+			}
+			opts.Images = images
+		}
 
-		if len(args) == 0 {
+		switch {
+		case !ui.IsInteractive() && attach.StdinPiped():
+			// Piped input becomes context, or the question itself when no question was given.
+			// Only pipes and files are read, since both end, so reading to EOF can't hang on a terminal or socket
+			piped, err := attach.FromStdin(attach.DefaultStdinLimit)
+			if err != nil {
+				return err
+			}
+			if piped.Truncated {
+				ui.ShowExecutionStatus(fmt.Sprintf("Piped input exceeds %d KB and was truncated", attach.DefaultStdinLimit/1024), "warning")
+			}
+
+			switch {
+			case strings.TrimSpace(piped.Content) == "":
+			case question == "":
+				question = strings.TrimSpace(piped.Content)
+			default:
+				opts.Attachments = append(opts.Attachments, piped)
+			}
+		case ui.IsInteractive() && question == "":
 			// If no question provided, prompt for it
-			question = ui.PromptForQuestion()
-		} else {
-			question = strings.Join(args, " ")
+			prompted, err := ui.PromptForQuestion()
+			if err != nil {
+				return err
+			}
+			question = prompted
 		}
 
 		if question == "" {
-			ui.ShowError("No question provided")
-			return
+			return types.NewError(types.ErrorUsage, errors.New("no question provided"))
 		}
 
-		ai.AskQuestion(question, ApiKey, Model, EnableCommands)
+		if err := ai.AskQuestion(question, opts); err != nil {
+			return err
+		}
+		ui.FinishOutput()
+		return nil
 	},
 }
 
 func init() {
+	askCmd.Flags().BoolVarP(&continueSession, "continue", "c", false, "Continue the most recent conversation")
+	askCmd.Flags().StringVar(&sessionID, "session", "", "Continue the conversation with the given session ID")
+	askCmd.Flags().StringArrayVarP(&attachFiles, "file", "f", nil, "Attach a file as context (repeatable, globs like 'src/**/*.go' allowed)")
+	askCmd.Flags().StringArrayVar(&attachDirs, "dir", nil, "Attach the text files in a directory, respecting .gitignore (repeatable)")
+	askCmd.Flags().StringArrayVar(&attachImages, "image", nil, "Send a PNG, JPEG or WebP image with the question (repeatable)")
+	askCmd.Flags().BoolVar(&agentMode, "agent", false, "Let the model run commands (each one confirmed) and see their output until the task is done")
+	askCmd.Flags().IntVar(&maxSteps, "max-steps", ai.DefaultMaxSteps, "Maximum number of model turns in agent mode")
+	askCmd.Flags().StringVar(&templateName, "template", "", "Ask using a prompt template from the templates directory (see: oracle templates list)")
+	askCmd.Flags().StringArrayVar(&templateVars, "var", nil, "Set a template variable as name=value (repeatable)")
+	askCmd.Flags().IntVar(&tokenBudget, "budget", 0, fmt.Sprintf("Token budget for the question and attachments (default %d, or TokenBudget in config)", attach.DefaultTokenBudget))
+	askCmd.Flags().BoolVar(&noCache, "no-cache", false, "Don't read or write the response cache")
+	askCmd.Flags().BoolVar(&refreshCache, "refresh", false, "Ask again even if the answer is cached, and cache the new answer")
+	askCmd.Flags().StringVarP(&outputMode, "output", "o", ui.OutputPretty, "Output format: pretty, raw (plain text), json (one object at the end) or ndjson (streamed events)")
+	askCmd.MarkFlagsMutuallyExclusive("no-cache", "refresh")
+	_ = askCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(ui.OutputModes, cobra.ShellCompDirectiveNoFileComp))
 	RootCmd.AddCommand(askCmd)
 }
 
+// setupAPIKeyIfNeeded runs the API key setup when the selected provider requires one
+func setupAPIKeyIfNeeded() error {
+	providerName, err := config.GetProvider(Provider)
+	if err != nil {
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	backend, err := config.GetBackend(Backend)
+	if err != nil {
+		return fmt.Errorf("failed to get backend: %w", err)
+	}
+
+	// Only the Gemini API requires a key, other providers and Vertex AI authenticate differently
+	if providerName != "gemini" || backend == provider.BackendVertexAI {
+		return nil
+	}
+
+	return checkAndSetupAPIKey()
+}
+
 // checkAndSetupAPIKey checks if API key is available and prompts for it if needed
 func checkAndSetupAPIKey() error {
 	// Check if API key is available from any source
@@ -64,11 +195,16 @@ func checkAndSetupAPIKey() error {
 		return nil
 	}
 
+	// Prompting would consume piped input, so require the key up front instead
+	if !ui.IsInteractive() {
+		return types.NewError(types.ErrorAuth, errors.New("API key is required. Set GOOGLE_AI_API_KEY environment variable or use --api-key flag"))
+	}
+
 	// No API key found anywhere, prompt for it
 	ui.ShowAPIKeyPrompt()
-	newAPIKey := ui.PromptForAPIKey()
-	if newAPIKey == "" {
-		return fmt.Errorf("API key is required to use Oracle")
+	newAPIKey, err := ui.PromptForAPIKey()
+	if err != nil {
+		return types.NewError(types.ErrorAuth, err)
 	}
 
 	// Save the API key to config
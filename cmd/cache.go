@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/simplyzetax/oracle/internal/cache"
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the response cache",
+	Long: `Inspect and clear the response cache.
+
+Answers to questions are kept under ~/.oracle/cache and reused when the
+same question is asked again with the same provider and endpoint, model,
+system prompt, context and generation parameters. Use --no-cache to bypass the cache for
+a question, or --refresh to ask again and replace the cached answer.
+
+Examples:
+  oracle cache stats
+  oracle cache clear`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show what the response cache holds",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := config.GetCacheSettings()
+		if err != nil {
+			return err
+		}
+
+		dir, err := cache.GetCacheDir()
+		if err != nil {
+			return fmt.Errorf("failed to get cache directory: %w", err)
+		}
+
+		stats, err := cache.Stat(settings.TTL)
+		if err != nil {
+			return err
+		}
+
+		ui.ShowCacheStats(dir, stats, settings.Enabled, settings.TTL, settings.MaxSize)
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := cache.Clear()
+		if err != nil {
+			return err
+		}
+
+		if removed == 1 {
+			ui.ShowSuccess("Removed 1 cached response")
+			return nil
+		}
+		ui.ShowSuccess(fmt.Sprintf("Removed %d cached responses", removed))
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd, cacheClearCmd)
+	RootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/simplyzetax/oracle/internal/ai"
+	"github.com/spf13/cobra"
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Start an interactive multi-turn conversation",
+	Long: `Start an interactive chat session that remembers previous turns.
+
+Slash commands:
+  /model [name]  Show or switch the model
+  /clear         Start a fresh conversation
+  /save [file]   Save the conversation as markdown
+  /exec          Run commands from the last answer
+  /help          Show available commands
+  /exit          Leave the chat (or press Ctrl-D)
+
+Examples:
+  oracle chat
+  oracle chat --provider ollama --model llama3
+  oracle chat --execute`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupAPIKeyIfNeeded(); err != nil {
+			return fmt.Errorf("failed to set up API key: %w", err)
+		}
+
+		return ai.RunChat(aiOptions())
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(chatCmd)
+}
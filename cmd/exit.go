@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, documented in the README so scripts can tell failures apart
+const (
+	exitGeneral       = 1
+	exitUsage         = 2
+	exitAuth          = 3
+	exitQuota         = 4
+	exitNetwork       = 5
+	exitSafety        = 6
+	exitCommandFailed = 7
+	// exitCancelled follows the shell convention for a process stopped by Ctrl-C
+	exitCancelled = 130
+)
+
+// exitCodes maps each kind of error to its exit code
+var exitCodes = map[types.ErrorKind]int{
+	types.ErrorUsage:         exitUsage,
+	types.ErrorAuth:          exitAuth,
+	types.ErrorQuota:         exitQuota,
+	types.ErrorNetwork:       exitNetwork,
+	types.ErrorSafety:        exitSafety,
+	types.ErrorCommandFailed: exitCommandFailed,
+	types.ErrorCancelled:     exitCancelled,
+}
+
+// exitCode returns the exit code for the error that ended a command
+func exitCode(err error) int {
+	if code, ok := exitCodes[types.KindOf(err)]; ok {
+		return code
+	}
+	return exitGeneral
+}
+
+// usageError marks an invalid flag as a usage error and points to the command's help
+func usageError(cmd *cobra.Command, err error) error {
+	return types.NewError(types.ErrorUsage, fmt.Errorf("%w\nRun '%s --help' for usage", err, cmd.CommandPath()))
+}
+
+// markUsageErrors makes the argument checks of every command report usage errors
+func markUsageErrors(cmd *cobra.Command) {
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(c *cobra.Command, a []string) error {
+			if err := args(c, a); err != nil {
+				return usageError(c, err)
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
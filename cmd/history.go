@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/simplyzetax/oracle/internal/history"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var historyLimit int
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse and search previous questions and answers",
+	Long: `Browse, search and remove previous questions and answers.
+
+Every exchange is stored under ~/.oracle/history together with the
+commands that were suggested, which of them were run and their exit codes.
+
+Examples:
+  oracle history list
+  oracle history search docker prune
+  oracle history show 20250101120000123-ab12
+  oracle history rm 20250101120000123-ab12`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent history entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := history.List()
+		if err != nil {
+			return fmt.Errorf("failed to list history: %w", err)
+		}
+
+		if historyLimit > 0 && len(entries) > historyLimit {
+			entries = entries[:historyLimit]
+		}
+
+		ui.ShowHistoryList(entries)
+		return nil
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a history entry in full",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := history.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		ui.ShowHistoryEntry(entry)
+		return nil
+	},
+}
+
+var historySearchCmd = &cobra.Command{
+	Use:   "search <terms...>",
+	Short: "Search questions, answers and commands",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := history.Search(strings.Join(args, " "))
+		if err != nil {
+			return fmt.Errorf("failed to search history: %w", err)
+		}
+
+		if historyLimit > 0 && len(entries) > historyLimit {
+			entries = entries[:historyLimit]
+		}
+
+		ui.ShowHistoryList(entries)
+		return nil
+	},
+}
+
+var historyRmCmd = &cobra.Command{
+	Use:   "rm <id...>",
+	Short: "Remove history entries",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, id := range args {
+			if err := history.Remove(id); err != nil {
+				return err
+			}
+			ui.ShowSuccess("Removed " + id)
+		}
+		return nil
+	},
+}
+
+func init() {
+	historyListCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum number of entries to show (0 for all)")
+	historySearchCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum number of entries to show (0 for all)")
+
+	historyCmd.AddCommand(historyListCmd, historyShowCmd, historySearchCmd, historyRmCmd)
+	RootCmd.AddCommand(historyCmd)
+}
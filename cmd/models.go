@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/ai"
+	"github.com/simplyzetax/oracle/internal/catalog"
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// completionFetchTimeout bounds fetching a model list during shell completion
+const completionFetchTimeout = 5 * time.Second
+
+var modelsRefresh bool
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List the models available from each provider",
+	Long: `List the models each configured provider can serve, with their context
+window, output limit and capabilities (vision, tools, thinking).
+
+Providers that aren't set up, like Gemini without an API key or Ollama when
+no server is running, are skipped. Model lists are cached for a day in
+~/.oracle/models.json and used to check --model and for shell completion.
+
+Examples:
+  oracle models
+  oracle models --refresh
+  oracle models --provider ollama
+  oracle models --provider openai --base-url http://localhost:8000/v1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		selected, err := config.GetProvider(Provider)
+		if err != nil {
+			return fmt.Errorf("failed to get provider: %w", err)
+		}
+
+		names := provider.Names()
+		if RootCmd.PersistentFlags().Changed("provider") {
+			names = []string{selected}
+		}
+
+		for _, name := range names {
+			opts := aiOptions()
+			opts.Provider = name
+			// Credentials and endpoints given as flags belong to the selected provider only
+			if name != selected {
+				opts.APIKey = ""
+				opts.BaseURL = ""
+			}
+
+			p, err := ai.NewProvider(ctx, opts)
+			if err != nil {
+				ui.ShowExecutionStatus("Skipped "+name+": "+err.Error(), "warning")
+				continue
+			}
+
+			list, fresh, err := catalog.Models(ctx, p, modelsRefresh)
+			if err != nil {
+				ui.ShowExecutionStatus("Skipped "+name+": "+err.Error(), "warning")
+				continue
+			}
+
+			ui.ShowModels(name, list.Models, time.Unix(list.FetchedAt, 0), fresh)
+		}
+		return nil
+	},
+}
+
+// completeModels completes --model with the selected provider's models, fetching them if none are cached
+func completeModels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionFetchTimeout)
+	defer cancel()
+	p, err := ai.NewProvider(ctx, aiOptions())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	models := catalog.Cached(p)
+	if len(models) == 0 {
+		if list, _, err := catalog.Models(ctx, p, false); err == nil {
+			models = list.Models
+		}
+	}
+
+	var completions []string
+	for _, m := range models {
+		if strings.HasPrefix(m.Name, toComplete) {
+			completions = append(completions, m.Name+"\t"+ui.ModelSummary(m))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	modelsCmd.Flags().BoolVar(&modelsRefresh, "refresh", false, "Fetch the model lists again instead of using the cache")
+
+	RootCmd.AddCommand(modelsCmd)
+}
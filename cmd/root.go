@@ -3,17 +3,44 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/simplyzetax/oracle/internal/ai"
 	"github.com/simplyzetax/oracle/internal/alias"
 	"github.com/simplyzetax/oracle/internal/config"
 	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/pkg/types"
 	"github.com/spf13/cobra"
 )
 
 var (
 	ApiKey         string
 	Model          string
+	Provider       string
+	BaseURL        string
+	Backend        string
+	Project        string
+	Location       string
+	Proxy          string
+	CABundle       string
 	EnableCommands bool
+	NoContext      bool
+	Persona        string
+	Verbose        bool
+	Brief          bool
+	PromptFile     string
+	Debug          bool
+	Timeout        time.Duration
+	Record         bool
+
+	// Generation parameters, only sent when the flag is given
+	Temperature    float32
+	TopP           float32
+	TopK           int
+	MaxTokens      int
+	StopSequences  []string
+	Seed           int
+	CandidateCount int
 )
 
 var RootCmd = &cobra.Command{
@@ -24,42 +51,154 @@ var RootCmd = &cobra.Command{
 Examples:
   oracle ask "What is the meaning of life?"
   oracle ask "Explain quantum computing" --model gemini-pro
-  oracle ask "Write a haiku about coding" --api-key your-key`,
+  oracle ask "Write a haiku about coding" --api-key your-key
+  oracle ask "Summarize this error" --provider gemini
+  oracle ask "Explain Go channels" --provider openai --model gpt-4o-mini
+  oracle ask "What is a monad?" --provider ollama --model llama3
+  oracle ask "Explain IAM roles" --backend vertex --project my-project --location us-central1`,
+	// The first run setup waits for the flags, to know the output mode
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		offerFirstRunSetup()
+	},
 }
 
 func Execute() {
-	// Check for first run and offer alias setup
-	if config.IsFirstRun() {
-		ui.ShowFirstRunWelcome()
-
-		if ui.ConfirmAliasSetup() {
-			if err := alias.SetupAlias(); err != nil {
-				ui.ShowError("Failed to set up alias automatically: " + err.Error())
-				ui.ShowAliasInstructions()
-			} else {
-				ui.ShowAliasSetupSuccess()
-			}
-		} else {
-			ui.ShowAliasInstructions()
-		}
+	markUsageErrors(RootCmd)
+	if err := RootCmd.Execute(); err != nil {
+		ui.ShowError(err)
+		os.Exit(exitCode(err))
+	}
+}
 
-		// Mark first run as complete
-		if err := config.MarkFirstRunComplete(); err != nil {
-			// Non-fatal error, just continue
-			fmt.Printf("Warning: Could not mark first run as complete: %v\n", err)
-		}
+// offerFirstRunSetup welcomes first-time users and offers to set up the alias
+func offerFirstRunSetup() {
+	// Never when input is piped, or when stdout carries machine-readable output
+	if !config.IsFirstRun() || !ui.IsInteractive() || (outputMode != "" && outputMode != ui.OutputPretty) {
+		return
+	}
 
-		fmt.Println() // Add spacing
+	ui.ShowFirstRunWelcome()
+
+	if ui.ConfirmAliasSetup() {
+		if err := alias.SetupAlias(); err != nil {
+			ui.ShowExecutionStatus("Failed to set up alias automatically: "+err.Error(), "error")
+			ui.ShowAliasInstructions()
+		} else {
+			ui.ShowAliasSetupSuccess()
+		}
+	} else {
+		ui.ShowAliasInstructions()
 	}
 
-	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	// Mark first run as complete
+	if err := config.MarkFirstRunComplete(); err != nil {
+		// Non-fatal error, just continue
+		fmt.Printf("Warning: Could not mark first run as complete: %v\n", err)
 	}
+
+	fmt.Println() // Add spacing
 }
 
 func init() {
-	RootCmd.PersistentFlags().StringVarP(&ApiKey, "api-key", "k", "", "Google AI API key (can also use GOOGLE_AI_API_KEY env var)")
-	RootCmd.PersistentFlags().StringVarP(&Model, "model", "m", "gemini-2.0-flash-exp", "AI model to use")
+	RootCmd.PersistentFlags().StringVarP(&ApiKey, "api-key", "k", "", "API key for the selected provider (can also use GOOGLE_AI_API_KEY or OPENAI_API_KEY env vars)")
+	RootCmd.PersistentFlags().StringVarP(&Model, "model", "m", "", "AI model to use (or Model in config, defaults to one per provider, see: oracle models)")
+	RootCmd.PersistentFlags().StringVarP(&Provider, "provider", "p", "", "Model provider to use (can also use ORACLE_PROVIDER env var or config)")
+	RootCmd.PersistentFlags().StringVar(&BaseURL, "base-url", "", "Custom API base URL for the selected provider (e.g. http://localhost:8000/v1)")
+	RootCmd.PersistentFlags().StringVar(&Backend, "backend", "", "Gemini backend to use: gemini (API key) or vertex (Vertex AI with ADC)")
+	RootCmd.PersistentFlags().StringVar(&Project, "project", "", "Google Cloud project for Vertex AI (can also use GOOGLE_CLOUD_PROJECT env var)")
+	RootCmd.PersistentFlags().StringVar(&Location, "location", "", "Google Cloud location for Vertex AI (can also use GOOGLE_CLOUD_LOCATION env var)")
+	RootCmd.PersistentFlags().StringVar(&Proxy, "proxy", "", "HTTPS proxy URL for API requests (defaults to HTTPS_PROXY env var)")
+	RootCmd.PersistentFlags().StringVar(&CABundle, "ca-bundle", "", "Path to an extra PEM CA bundle to trust (can also use ORACLE_CA_BUNDLE env var)")
 	RootCmd.PersistentFlags().BoolVarP(&EnableCommands, "execute", "x", false, "Enable command execution (allows Oracle to run shell commands)")
+	RootCmd.PersistentFlags().BoolVar(&NoContext, "no-context", false, "Don't tell the model about your OS, shell, directory and project")
+	RootCmd.PersistentFlags().StringVar(&Persona, "persona", "", "Persona from the config to answer as (can also use ORACLE_PERSONA env var)")
+	RootCmd.PersistentFlags().BoolVar(&Verbose, "verbose", false, "Ask for a thorough, in-depth answer")
+	RootCmd.PersistentFlags().BoolVar(&Brief, "brief", false, "Ask for a short answer of at most 3 sentences")
+	RootCmd.PersistentFlags().StringVar(&PromptFile, "system-prompt-file", "", "Replace the base system prompt with the contents of a file")
+	RootCmd.PersistentFlags().BoolVar(&Debug, "debug", false, "Show the effective provider, model and generation parameters")
+	RootCmd.PersistentFlags().DurationVar(&Timeout, "timeout", 0, "Give up on a response that hasn't finished after this long, e.g. 90s (no limit by default)")
+	RootCmd.PersistentFlags().BoolVar(&Record, "record", false, "Record responses as cassettes for --provider replay (can also use ORACLE_RECORD env var)")
+	RootCmd.MarkFlagsMutuallyExclusive("verbose", "brief")
+	// Errors are rendered once by Execute, which also picks the exit code
+	RootCmd.SilenceErrors = true
+	RootCmd.SilenceUsage = true
+	RootCmd.SetFlagErrorFunc(usageError)
+	_ = RootCmd.RegisterFlagCompletionFunc("model", completeModels)
+
+	RootCmd.PersistentFlags().Float32Var(&Temperature, "temperature", 0, "Sampling temperature (default 0.7, or Generation.Temperature in config)")
+	RootCmd.PersistentFlags().Float32Var(&TopP, "top-p", 0, "Nucleus sampling probability mass, between 0 and 1")
+	RootCmd.PersistentFlags().IntVar(&TopK, "top-k", 0, "Sample from the k most likely tokens (not supported by openai)")
+	RootCmd.PersistentFlags().IntVar(&MaxTokens, "max-tokens", 0, "Maximum number of tokens to generate")
+	RootCmd.PersistentFlags().StringArrayVar(&StopSequences, "stop", nil, "Stop generating at this sequence (repeatable)")
+	RootCmd.PersistentFlags().IntVar(&Seed, "seed", 0, "Seed for more reproducible sampling")
+	RootCmd.PersistentFlags().IntVar(&CandidateCount, "candidates", 0, "Number of candidates to generate, only the first is shown (not supported by ollama)")
+}
+
+// aiOptions builds the AI options from the global flags
+func aiOptions() ai.Options {
+	// Only an explicit --model overrides the persona and config, the default is applied last
+	model := ""
+	if RootCmd.PersistentFlags().Changed("model") {
+		model = Model
+	}
+
+	verbosity := ""
+	switch {
+	case Verbose:
+		verbosity = ai.VerbosityVerbose
+	case Brief:
+		verbosity = ai.VerbosityBrief
+	}
+
+	return ai.Options{
+		APIKey:         ApiKey,
+		Model:          model,
+		Provider:       Provider,
+		BaseURL:        BaseURL,
+		Backend:        Backend,
+		Project:        Project,
+		Location:       Location,
+		Proxy:          Proxy,
+		CABundle:       CABundle,
+		EnableCommands: EnableCommands,
+		NoContext:      NoContext,
+
+		Persona:          Persona,
+		Verbosity:        verbosity,
+		SystemPromptFile: PromptFile,
+
+		Generation: generationFlags(),
+		Debug:      Debug,
+		Timeout:    Timeout,
+		Record:     Record,
+	}
+}
+
+// generationFlags collects the generation parameters given on the command line
+func generationFlags() types.GenerationConfig {
+	flags := RootCmd.PersistentFlags()
+
+	var g types.GenerationConfig
+	if flags.Changed("temperature") {
+		g.Temperature = &Temperature
+	}
+	if flags.Changed("top-p") {
+		g.TopP = &TopP
+	}
+	if flags.Changed("top-k") {
+		g.TopK = &TopK
+	}
+	if flags.Changed("max-tokens") {
+		g.MaxOutputTokens = &MaxTokens
+	}
+	if flags.Changed("stop") {
+		g.StopSequences = StopSequences
+	}
+	if flags.Changed("seed") {
+		g.Seed = &Seed
+	}
+	if flags.Changed("candidates") {
+		g.CandidateCount = &CandidateCount
+	}
+	return g
 }
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/templates"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:     "templates",
+	Aliases: []string{"template"},
+	Short:   "List and inspect reusable prompt templates",
+	Long: `List and inspect reusable prompt templates.
+
+Templates are Go text/template files ending in .tmpl, stored in ~/.oracle/templates
+(or the directory in ORACLE_TEMPLATES_DIR or TemplatesDir in the config, which makes
+it easy to share a directory of templates through a team repository). A template
+declares its variables in a comment at the top:
+
+  {{/*
+  description: Debug a crashing Kubernetes workload
+  var: ns | Namespace the workload runs in | default
+  var: pod | Name of the failing pod
+  */}}
+  Pod {{.pod}} in namespace {{.ns}} keeps restarting. How do I find out why?
+
+Examples:
+  oracle templates list
+  oracle templates show k8s-debug
+  oracle ask --template k8s-debug --var ns=prod --var pod=api-7d9f`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := config.GetTemplatesDir()
+		if err != nil {
+			return fmt.Errorf("failed to get templates directory: %w", err)
+		}
+
+		list, err := templates.List(dir)
+		if err != nil {
+			return err
+		}
+
+		ui.ShowTemplateList(dir, list)
+		return nil
+	},
+}
+
+var templatesShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a template's variables and source",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := config.GetTemplatesDir()
+		if err != nil {
+			return fmt.Errorf("failed to get templates directory: %w", err)
+		}
+
+		t, err := templates.Load(dir, args[0])
+		if err != nil {
+			return err
+		}
+
+		source, err := os.ReadFile(t.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+
+		ui.ShowTemplate(t, strings.TrimSpace(string(source)))
+		return nil
+	},
+}
+
+func init() {
+	templatesCmd.AddCommand(templatesListCmd, templatesShowCmd)
+	RootCmd.AddCommand(templatesCmd)
+}
+
+// renderTemplate loads a template and renders it with the given key=value pairs,
+// asking for any variables that are still missing
+func renderTemplate(name string, pairs []string) (string, error) {
+	dir, err := config.GetTemplatesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get templates directory: %w", err)
+	}
+
+	t, err := templates.Load(dir, name)
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return "", fmt.Errorf("invalid --var %q, expected name=value", pair)
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+
+	if missing := t.Missing(values); len(missing) > 0 {
+		if !ui.IsInteractive() {
+			names := make([]string, len(missing))
+			for i, v := range missing {
+				names[i] = v.Name
+			}
+			return "", fmt.Errorf("template %q needs values for: %s (use --var name=value)", t.Name, strings.Join(names, ", "))
+		}
+
+		answers, err := ui.PromptForTemplateVars(t.Name, missing)
+		if err != nil {
+			return "", fmt.Errorf("failed to get template variables: %w", err)
+		}
+		for k, v := range answers {
+			values[k] = v
+		}
+	}
+
+	return t.Render(values)
+}
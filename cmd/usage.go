@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageBy   string
+	usageDays int
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report token usage and estimated cost",
+	Long: `Report the tokens used by previous requests and their estimated cost.
+
+The token counts of every request are kept in ~/.oracle/usage.jsonl.
+Costs are estimated from a built-in table of list prices, which can be
+extended or overridden with Prices in the config.
+
+Examples:
+  oracle usage
+  oracle usage --by model
+  oracle usage --by persona --days 7`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		since := time.Time{}
+		if usageDays > 0 {
+			since = usage.StartOfDay(now).AddDate(0, 0, 1-usageDays)
+		}
+
+		records, err := usage.Load(since)
+		if err != nil {
+			return fmt.Errorf("failed to load usage: %w", err)
+		}
+
+		prices, err := config.GetPrices()
+		if err != nil {
+			return err
+		}
+
+		summaries, err := usage.Summarize(records, usageBy, prices)
+		if err != nil {
+			return err
+		}
+		ui.ShowUsageReport(usageBy, summaries)
+
+		budget, err := config.GetBudget()
+		if err != nil {
+			return err
+		}
+		if budget.DailyTokens > 0 || budget.MonthlyTokens > 0 {
+			monthRecords, err := usage.Load(usage.StartOfMonth(now))
+			if err != nil {
+				return fmt.Errorf("failed to load usage: %w", err)
+			}
+			ui.ShowBudgetStatus(budget, usage.TokensSince(monthRecords, usage.StartOfDay(now)), usage.TokensSince(monthRecords, usage.StartOfMonth(now)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	usageCmd.Flags().StringVar(&usageBy, "by", usage.ByDay, "Group usage by day, model or persona")
+	usageCmd.Flags().IntVar(&usageDays, "days", 30, "Only include the last N days (0 for all)")
+
+	RootCmd.AddCommand(usageCmd)
+}
@@ -32,10 +32,7 @@ var versionCmd = &cobra.Command{
 		fmt.Println("  - Command execution (with --execute flag)")
 		fmt.Println("  - Safe command detection and confirmation")
 		fmt.Println()
-		fmt.Println("Supported Models:")
-		fmt.Println("  - gemini-2.0-flash-exp (default)")
-		fmt.Println("  - gemini-pro")
-		fmt.Println("  - gemini-pro-vision")
+		fmt.Println("Default model: chosen per provider (run `oracle models` to list the available models)")
 		fmt.Println()
 		fmt.Println("Repository: https://github.com/simplyzetax/oracle")
 	},
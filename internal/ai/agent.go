@@ -0,0 +1,217 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/simplyzetax/oracle/internal/commands"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// DefaultMaxSteps is how many model turns an agent run may take when no limit is given
+const DefaultMaxSteps = 10
+
+// taskDoneTool is the name of the tool the model calls to end an agent run
+const taskDoneTool = "task_done"
+
+// agentPrompt is added to the system prompt in agent mode
+const agentPrompt = `
+
+You are working in agent mode. To make progress on the task, call the propose_commands tool. The user confirms each command, then you receive its exit code and output (or a note that it was declined) and can decide the next step. Run commands that inspect the system before ones that change it. When the task is complete, or can't be completed, call the task_done tool with a short summary instead of proposing more commands.`
+
+// agentResult is what an agent run did, for the history store
+type agentResult struct {
+	response string
+	records  []types.CommandRecord
+	// failed is set when the model declared the task done without completing it
+	failed bool
+}
+
+// taskDoneDeclaration declares the tool the model calls when it considers the task finished
+func taskDoneDeclaration() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        taskDoneTool,
+		Description: "Declare that the task is finished, or that it can't be finished, and summarize the outcome.",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"summary": {
+					Type:        genai.TypeString,
+					Description: "What was done and the final result",
+				},
+				"success": {
+					Type:        genai.TypeBoolean,
+					Description: "Whether the task was completed",
+				},
+			},
+			Required: []string{"summary"},
+		},
+	}
+}
+
+// runAgent lets the model work on a task over several steps, feeding the output of every
+// confirmed command back to it until it declares the task done or maxSteps is reached
+func runAgent(ctx context.Context, p provider.Provider, s *settings, contents []*genai.Content, maxSteps int) (*agentResult, error) {
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxSteps
+	}
+
+	result := &agentResult{}
+	var transcript []string
+
+	for step := 1; step <= maxSteps; step++ {
+		ui.ShowAgentStep(step, maxSteps)
+
+		req := newRequest(p, s, contents, true)
+		req.SystemPrompt += agentPrompt
+		req.Tools = append(req.Tools, taskDoneDeclaration())
+
+		response, calls, err := streamResponse(ctx, p, s, req)
+		if err != nil {
+			return result, err
+		}
+		if strings.TrimSpace(response) != "" {
+			transcript = append(transcript, response)
+		}
+
+		// A reply without tool calls is a plain answer, so there is nothing left to run
+		if len(calls) == 0 {
+			result.response = strings.Join(transcript, "\n\n")
+			return result, nil
+		}
+
+		contents = append(contents, modelTurn(response, calls))
+
+		// A turn that declares the task done ends the run, so commands proposed in the same turn are
+		// declined rather than run without the model ever seeing their output
+		if done := findCall(calls, taskDoneTool); done != nil {
+			if len(calls) > 1 {
+				ui.ShowExecutionStatus("The task was declared done, so the other tool calls of that turn were declined", "warning")
+			}
+			summary, _ := done.Args["summary"].(string)
+			success, ok := done.Args["success"].(bool)
+			ui.ShowAgentDone(summary, !ok || success)
+			result.failed = ok && !success
+			if summary != "" {
+				transcript = append(transcript, summary)
+			}
+			result.response = strings.Join(transcript, "\n\n")
+			return result, nil
+		}
+
+		var responses []*genai.Part
+		for _, call := range calls {
+			switch call.Name {
+			case commands.ProposeCommandsTool:
+				outcome, records := runProposals(call)
+				result.records = append(result.records, records...)
+				responses = append(responses, functionResponse(call, outcome))
+			default:
+				responses = append(responses, functionResponse(call, map[string]any{"error": "unknown tool " + call.Name}))
+			}
+		}
+
+		contents = append(contents, genai.NewContentFromParts(responses, genai.RoleUser))
+	}
+
+	ui.ShowExecutionStatus(fmt.Sprintf("Stopped after %d steps without the task being declared done", maxSteps), "warning")
+	result.response = strings.Join(transcript, "\n\n")
+	return result, nil
+}
+
+// runProposals asks the user to confirm each proposed command, runs the confirmed ones and
+// returns their results for the model along with records for the history store
+func runProposals(call *genai.FunctionCall) (map[string]any, []types.CommandRecord) {
+	proposals, err := commands.ParseProposals([]*genai.FunctionCall{call})
+	if err != nil {
+		return map[string]any{"error": err.Error()}, nil
+	}
+	if len(proposals) == 0 {
+		return map[string]any{"error": "no runnable commands were proposed, unsafe commands are rejected"}, nil
+	}
+
+	var results []map[string]any
+	var records []types.CommandRecord
+	for _, proposal := range proposals {
+		ui.ShowCommandDetected(proposal)
+	}
+
+	for i, proposal := range proposals {
+		ui.ShowCommandProposal(proposal)
+		if !ui.ConfirmExecution(proposal.Command) {
+			records = append(records, types.CommandRecord{Command: proposal.Command})
+			results = append(results, map[string]any{
+				"command":  proposal.Command,
+				"executed": false,
+				"reason":   "declined by the user",
+			})
+			continue
+		}
+
+		exitCode, output, err := commands.CaptureCommand(proposal.Command, proposal.WorkingDir)
+		records = append(records, types.CommandRecord{Command: proposal.Command, Executed: true, ExitCode: exitCode})
+
+		commandResult := map[string]any{
+			"command":   proposal.Command,
+			"executed":  true,
+			"exit_code": exitCode,
+			"stdout":    output.Stdout,
+			"stderr":    output.Stderr,
+		}
+		if output.Truncated {
+			commandResult["note"] = fmt.Sprintf("output was truncated to the last %d bytes of each stream", commands.MaxCapturedOutput)
+		}
+		if err != nil && exitCode == -1 {
+			commandResult["error"] = err.Error()
+		}
+		results = append(results, commandResult)
+
+		// Later commands usually depend on earlier ones, so let the model react to a failure first
+		if err != nil {
+			for _, skipped := range proposals[i+1:] {
+				records = append(records, types.CommandRecord{Command: skipped.Command})
+				results = append(results, map[string]any{
+					"command":  skipped.Command,
+					"executed": false,
+					"reason":   "skipped because an earlier command failed",
+				})
+			}
+			break
+		}
+	}
+
+	return map[string]any{"results": results}, records
+}
+
+// findCall returns the first call of the named tool, or nil when there is none
+func findCall(calls []*genai.FunctionCall, name string) *genai.FunctionCall {
+	for _, call := range calls {
+		if call.Name == name {
+			return call
+		}
+	}
+	return nil
+}
+
+// modelTurn rebuilds the model's reply, including its function calls, for the conversation
+func modelTurn(response string, calls []*genai.FunctionCall) *genai.Content {
+	var parts []*genai.Part
+	if response != "" {
+		parts = append(parts, genai.NewPartFromText(response))
+	}
+	for _, call := range calls {
+		parts = append(parts, &genai.Part{FunctionCall: call})
+	}
+	return genai.NewContentFromParts(parts, genai.RoleModel)
+}
+
+// functionResponse creates the part answering a function call
+func functionResponse(call *genai.FunctionCall, response map[string]any) *genai.Part {
+	part := genai.NewPartFromFunctionResponse(call.Name, response)
+	part.FunctionResponse.ID = call.ID
+	return part
+}
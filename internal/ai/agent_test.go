@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestAgentDeclinesOtherCallsWithTaskDone(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ORACLE_PROVIDER", "")
+	t.Setenv("ORACLE_RECORD", "")
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"propose_commands","arguments":"{\"commands\":[{\"command\":\"echo checked\"}]}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","function":{"name":"task_done","arguments":"{\"summary\":\"All set\",\"success\":true}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	opts := Options{Provider: "openai", BaseURL: server.URL, Model: "gpt-4o-mini", NoContext: true}
+	p, err := NewProvider(ctx, opts)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	s, err := resolveSettings(p, opts)
+	if err != nil {
+		t.Fatalf("resolveSettings: %v", err)
+	}
+
+	contents := []*genai.Content{genai.NewContentFromText("Check the setup", genai.RoleUser)}
+	result, err := runAgent(ctx, p, s, contents, 3)
+	if err != nil {
+		t.Fatalf("runAgent: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("server got %d requests, want the run to end with the task_done turn", requests)
+	}
+	if len(result.records) != 0 {
+		t.Errorf("records = %+v, want the command proposed with task_done declined", result.records)
+	}
+	if result.response != "All set" || result.failed {
+		t.Errorf("result = %+v, want the successful summary", result)
+	}
+}
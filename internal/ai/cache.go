@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/cache"
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"google.golang.org/genai"
+)
+
+// cacheKeyFields are everything that determines a response, hashed into the cache key
+type cacheKeyFields struct {
+	Provider string
+	// Endpoint keeps apart servers that serve different models under the same name
+	Endpoint string
+	Request  *provider.Request
+}
+
+// cachedResponse answers from the response cache when an identical request was answered recently,
+// and otherwise asks the model, or its fallbacks, and stores the response
+func cachedResponse(ctx context.Context, p provider.Provider, s *settings, contents []*genai.Content, opts Options) (*answer, error) {
+	cacheSettings, err := config.GetCacheSettings()
+	if err != nil {
+		return nil, err
+	}
+	// A cached answer would skip the provider, so recording always asks the model to write a cassette
+	if !cacheSettings.Enabled || opts.NoCache || config.GetRecord(opts.Record) {
+		return askWithFallback(ctx, p, s, contents, opts)
+	}
+
+	// The key describes the question asked of the configured model, whichever model answered it
+	req := newRequest(p, s, contents, opts.EnableCommands)
+	key, err := cache.Key(cacheKeyFields{Provider: p.Name(), Endpoint: p.Endpoint(), Request: req})
+	if err != nil {
+		ui.ShowExecutionStatus("Could not use the response cache: "+err.Error(), "warning")
+		return askWithFallback(ctx, p, s, contents, opts)
+	}
+
+	if !opts.RefreshCache {
+		if entry, ok := cache.Get(key, cacheSettings.TTL); ok {
+			ui.StartResponseStream()
+			ui.StreamResponseText(entry.Response)
+			ui.EndResponseStream()
+			ui.ShowCachedNotice(entry.Provider, entry.Model, time.Unix(entry.CreatedAt, 0))
+
+			cachedReq := *req
+			cachedReq.Model = entry.Model
+			if entry.NoTools {
+				cachedReq.Tools = nil
+			}
+			return &answer{provider: entry.Provider, req: &cachedReq, text: entry.Response, calls: entry.FunctionCalls}, nil
+		}
+	}
+
+	result, err := askWithFallback(ctx, p, s, contents, opts)
+	if err != nil {
+		return result, err
+	}
+
+	entry := &cache.Entry{
+		Key:           key,
+		Provider:      result.provider,
+		Model:         result.req.Model,
+		Response:      result.text,
+		FunctionCalls: result.calls,
+		NoTools:       len(result.req.Tools) == 0,
+	}
+	if err := cache.Put(entry, cacheSettings.TTL, cacheSettings.MaxSize); err != nil {
+		ui.ShowExecutionStatus("Could not cache the response: "+err.Error(), "warning")
+	}
+	return result, nil
+}
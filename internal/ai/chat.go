@@ -0,0 +1,218 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/commands"
+	"github.com/simplyzetax/oracle/internal/history"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// ChatSession holds the state of an interactive multi-turn conversation
+type ChatSession struct {
+	id        string
+	provider  provider.Provider
+	opts      Options
+	settings  *settings
+	history   []*genai.Content
+	lastEntry *types.HistoryEntry
+	// lastProposals are the commands proposed in the most recent reply, for /exec
+	lastProposals []types.CommandProposal
+}
+
+// RunChat starts an interactive chat session that reads prompts until Ctrl-D
+func RunChat(opts Options) error {
+	ctx := context.Background()
+
+	p, err := NewProvider(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	settings, err := resolveSettings(p, opts)
+	if err != nil {
+		return err
+	}
+	if err := checkModel(ctx, p, settings.model); err != nil {
+		return types.NewError(types.ErrorUsage, err)
+	}
+
+	session := &ChatSession{
+		id:       history.NewID(),
+		provider: p,
+		opts:     opts,
+		settings: settings,
+	}
+
+	ui.ShowChatWelcome(p.Name(), session.settings.model)
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		ui.ShowChatPrompt()
+
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+
+		input := strings.TrimSpace(line)
+		switch {
+		case input == "":
+		case strings.HasPrefix(input, "/"):
+			if !session.handleCommand(input) {
+				return nil
+			}
+		default:
+			session.Send(ctx, input)
+		}
+
+		// Ctrl-D ends the session
+		if errors.Is(err, io.EOF) {
+			fmt.Println()
+			ui.ShowChatGoodbye()
+			return nil
+		}
+	}
+}
+
+// Send adds a user turn to the conversation and streams the model's reply
+func (s *ChatSession) Send(ctx context.Context, text string) {
+	// Ctrl-C stops this response and keeps the session going
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	s.history = append(s.history, genai.NewContentFromText(text, genai.RoleUser))
+	askedAt := time.Now()
+
+	req := newRequest(s.provider, s.settings, s.history, s.opts.EnableCommands)
+	response, calls, err := streamResponse(ctx, s.provider, s.settings, req)
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) && response != "" {
+		// Keep the partial answer in the conversation, but don't offer to run commands from it
+		ui.ShowExecutionStatus("Stopped, the partial response was kept", "warning")
+		s.lastProposals = nil
+		s.history = append(s.history, genai.NewContentFromText(response, genai.RoleModel))
+		s.lastEntry = recordExchange(s.id, s.provider.Name(), s.settings.model, s.settings.model, text, nil, response, askedAt, nil)
+		return
+	}
+	if err != nil {
+		// Drop the unanswered turn so the conversation stays consistent
+		s.history = s.history[:len(s.history)-1]
+		if errors.Is(ctx.Err(), context.Canceled) {
+			ui.ShowExecutionStatus("Stopped before a response arrived", "warning")
+			return
+		}
+		ui.ShowExecutionStatus("Error generating content: "+err.Error(), "error")
+		return
+	}
+
+	s.lastProposals = commandProposals(req, response, calls)
+	if strings.TrimSpace(response) == "" {
+		response = commands.FormatProposals(s.lastProposals)
+	}
+	s.history = append(s.history, genai.NewContentFromText(response, genai.RoleModel))
+
+	records := handleCommands(s.lastProposals, s.opts.EnableCommands)
+	s.lastEntry = recordExchange(s.id, s.provider.Name(), s.settings.model, s.settings.model, text, nil, response, askedAt, records)
+}
+
+// handleCommand runs a slash command and reports whether the session should continue
+func (s *ChatSession) handleCommand(input string) bool {
+	fields := strings.Fields(input)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "/exit", "/quit":
+		ui.ShowChatGoodbye()
+		return false
+	case "/help":
+		ui.ShowChatHelp()
+	case "/model":
+		if len(args) == 0 {
+			ui.ShowExecutionStatus("Current model: "+s.settings.model, "info")
+			break
+		}
+		if err := checkModel(context.Background(), s.provider, args[0]); err != nil {
+			ui.ShowExecutionStatus(err.Error(), "error")
+			break
+		}
+		if err := s.settings.useModel(s.provider, args[0]); err != nil {
+			ui.ShowExecutionStatus(err.Error(), "error")
+			break
+		}
+		ui.ShowExecutionStatus("Switched model to "+s.settings.model, "success")
+	case "/clear":
+		s.history = nil
+		s.lastEntry = nil
+		s.lastProposals = nil
+		s.id = history.NewID()
+		ui.ShowExecutionStatus("Conversation cleared", "success")
+	case "/save":
+		path := fmt.Sprintf("oracle-chat-%s.md", time.Now().Format("20060102-150405"))
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if err := s.Save(path); err != nil {
+			ui.ShowExecutionStatus("Failed to save conversation: "+err.Error(), "error")
+			break
+		}
+		ui.ShowExecutionStatus("Conversation saved to "+path, "success")
+	case "/exec":
+		if s.lastResponse() == "" {
+			ui.ShowExecutionStatus("No response to run commands from yet", "warning")
+			break
+		}
+		if len(s.lastProposals) == 0 {
+			ui.ShowExecutionStatus("The last response has no commands to run", "warning")
+			break
+		}
+		records := handleCommands(s.lastProposals, true)
+		// Keep the history entry of the last turn in sync with what was run
+		if s.lastEntry != nil && len(records) > 0 {
+			s.lastEntry.Commands = records
+			if err := history.Save(s.lastEntry); err != nil {
+				ui.ShowExecutionStatus("Could not save history: "+err.Error(), "warning")
+			}
+		}
+	default:
+		ui.ShowExecutionStatus(fmt.Sprintf("Unknown command %s, type /help for a list of commands", name), "warning")
+	}
+
+	return true
+}
+
+// lastResponse returns the text of the most recent model turn
+func (s *ChatSession) lastResponse() string {
+	for i := len(s.history) - 1; i >= 0; i-- {
+		if s.history[i].Role == genai.RoleModel {
+			return provider.ContentText(s.history[i])
+		}
+	}
+	return ""
+}
+
+// Save writes the conversation to a markdown file
+func (s *ChatSession) Save(path string) error {
+	var transcript strings.Builder
+	fmt.Fprintf(&transcript, "# Oracle chat (%s)\n\n", s.settings.model)
+
+	for _, content := range s.history {
+		speaker := "You"
+		if content.Role == genai.RoleModel {
+			speaker = "Oracle"
+		}
+		fmt.Fprintf(&transcript, "## %s\n\n%s\n\n", speaker, provider.ContentText(content))
+	}
+
+	return os.WriteFile(path, []byte(transcript.String()), 0644)
+}
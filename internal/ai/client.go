@@ -2,83 +2,458 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"time"
 
+	"github.com/simplyzetax/oracle/internal/attach"
 	"github.com/simplyzetax/oracle/internal/commands"
 	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/history"
+	"github.com/simplyzetax/oracle/internal/provider"
 	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/pkg/types"
 	"google.golang.org/genai"
 )
 
-// AskQuestion handles the AI interaction with streaming response and optional command execution
-func AskQuestion(question, apiKey, model string, enableCommands bool) {
+// Options configures how a question is answered
+type Options struct {
+	APIKey         string
+	Model          string
+	Provider       string
+	BaseURL        string
+	Backend        string
+	Project        string
+	Location       string
+	Proxy          string
+	CABundle       string
+	EnableCommands bool
+
+	// Continue resumes the most recent session, SessionID resumes a specific one
+	Continue  bool
+	SessionID string
+
+	// Attachments are sent as context blocks after the question, within TokenBudget tokens
+	Attachments []*attach.Attachment
+	TokenBudget int
+
+	// Images are sent inline with the question for vision-capable models
+	Images []*attach.Image
+
+	// Agent lets the model run commands and see their output for up to MaxSteps turns
+	Agent    bool
+	MaxSteps int
+
+	// NoContext leaves details about the user's environment out of the system prompt
+	NoContext bool
+
+	// Persona selects a preset from the config, Verbosity overrides its answer length
+	// and SystemPromptFile replaces the base system prompt
+	Persona          string
+	Verbosity        string
+	SystemPromptFile string
+
+	// Generation overrides the sampling parameters from the persona and config, value by value
+	Generation types.GenerationConfig
+
+	// Debug shows the effective provider, model and generation parameters before asking
+	Debug bool
+
+	// NoCache bypasses the response cache, RefreshCache asks again and replaces the cached response
+	NoCache      bool
+	RefreshCache bool
+
+	// Timeout limits how long each model request may take, including retries, zero means no limit
+	Timeout time.Duration
+
+	// Record saves every response as a cassette for the replay provider
+	Record bool
+}
+
+// commandToolPrompt is added to the system prompt when commands are proposed through the tool
+const commandToolPrompt = `
+
+When you suggest shell commands, also call the propose_commands tool with each of them so the user can review and run them. Rate the risk of each command honestly and mark commands that need root privileges.`
+
+// NewProvider resolves the configured provider and its settings and creates it
+func NewProvider(ctx context.Context, opts Options) (provider.Provider, error) {
+	providerName, err := config.GetProvider(opts.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+
 	// Get API key from parameter, environment, or config
-	finalAPIKey, err := config.GetAPIKey(apiKey)
+	apiKey, err := config.GetProviderAPIKey(providerName, opts.APIKey)
 	if err != nil {
-		ui.ShowError("Failed to get API key: " + err.Error())
-		return
+		return nil, fmt.Errorf("failed to get API key: %w", err)
 	}
 
-	if finalAPIKey == "" {
-		ui.ShowError("API key is required. Set GOOGLE_AI_API_KEY environment variable or use --api-key flag")
-		return
+	baseURL, err := config.GetBaseURL(providerName, opts.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base URL: %w", err)
 	}
 
-	ctx := context.Background()
+	backend, err := config.GetBackend(opts.Backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backend: %w", err)
+	}
 
-	// Create client
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: finalAPIKey,
+	project, err := config.GetProject(opts.Project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	location, err := config.GetLocation(opts.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+
+	proxy, err := config.GetProxy(opts.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy: %w", err)
+	}
+
+	caBundle, err := config.GetCABundle(opts.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA bundle: %w", err)
+	}
+
+	// Only replace the default HTTP client when custom network settings are given
+	var httpClient *http.Client
+	if proxy != "" || caBundle != "" {
+		httpClient, err = provider.NewHTTPClient(proxy, caBundle)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cassetteDir, err := config.GetCassetteDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cassette directory: %w", err)
+	}
+
+	p, err := provider.New(ctx, providerName, provider.Options{
+		APIKey:      apiKey,
+		BaseURL:     baseURL,
+		HTTPClient:  httpClient,
+		Backend:     backend,
+		Project:     project,
+		Location:    location,
+		CassetteDir: cassetteDir,
 	})
 	if err != nil {
-		ui.ShowError("Failed to create AI client: " + err.Error())
-		return
+		return nil, err
+	}
+
+	// The recorder sits inside the retries, so a retried rate limit is replaced by the response that followed
+	if config.GetRecord(opts.Record) {
+		if providerName == provider.ReplayProvider {
+			return nil, fmt.Errorf("can't record with the %s provider, choose the provider to record from", provider.ReplayProvider)
+		}
+		p = provider.WithRecorder(p, cassetteDir)
+	}
+
+	// Rate limits and server errors are retried as long as nothing has been streamed yet
+	policy := provider.DefaultRetryPolicy
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		ui.ShowExecutionStatus(fmt.Sprintf("%v, retrying in %s (attempt %d of %d)", err, delay.Round(100*time.Millisecond), attempt+1, policy.MaxAttempts), "warning")
+	}
+	return provider.WithRetry(p, policy), nil
+}
+
+// AskQuestion handles the AI interaction with streaming response and optional command execution
+func AskQuestion(question string, opts Options) error {
+	// Ctrl-C stops the response but keeps what has arrived so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Create the model provider
+	p, err := NewProvider(ctx, opts)
+	if err != nil {
+		return err
 	}
 
-	// Simplified system prompt for commands
-	systemPrompt := `You are Oracle, an AI assistant that provides answers and executable shell commands.
-Format commands clearly using:
-- Code blocks with triple backticks for multi-line commands
-- Inline backticks for single commands
-- Prefix with $ for commands
+	// Resolve the model, system prompt and generation parameters from the flags, persona and config
+	settings, err := resolveSettings(p, opts)
+	if err != nil {
+		return err
+	}
+	if err := checkModel(ctx, p, settings.model); err != nil {
+		return types.NewError(types.ErrorUsage, err)
+	}
+	opts.Model = settings.model
 
-Explain what commands do before suggesting them. Avoid dangerous commands and keep responses concise. Again, keep the response length to a maximum of 3 sentences.`
+	sessionID, turns, err := loadSession(opts)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	// Attach piped input and files to the question, shrinking them to fit the budget. The history
+	// keeps the question as asked with only the names and sizes of the attachments
+	prompt := question
+	var attached []types.AttachmentRecord
+	if len(opts.Attachments) > 0 || len(opts.Images) > 0 {
+		attachments, err := fitAttachments(ctx, p, question, opts)
+		if err != nil {
+			return err
+		}
+		prompt = attach.Format(question, attachments)
+		attached = attachmentRecords(attachments, opts.Images)
+	}
+	questionContent := newQuestionContent(prompt, opts.Images)
+	if len(turns) > 0 {
+		var dropped int
+		turns, dropped = trimHistory(ctx, p, opts.Model, turns, questionContent)
+		ui.ShowExecutionStatus(describeSession(sessionID, len(turns)/2, dropped), "info")
+	}
+
+	askedAt := time.Now()
+
+	if opts.Agent {
+		if !p.SupportsTools() {
+			return types.NewError(types.ErrorUsage, fmt.Errorf("agent mode needs tool support, which the %s provider doesn't have", p.Name()))
+		}
+		result, err := runAgent(ctx, p, settings, append(turns, questionContent), opts.MaxSteps)
+		if result != nil && (result.response != "" || len(result.records) > 0) {
+			recordExchange(sessionID, p.Name(), settings.model, settings.model, question, attached, result.response, askedAt, result.records)
+		}
+		if err != nil {
+			return streamError(ctx, err, result != nil && result.response != "")
+		}
+		if result.failed {
+			return types.NewError(types.ErrorCommandFailed, errors.New("the task was not completed"))
+		}
+		return nil
+	}
+
+	result, err := cachedResponse(ctx, p, settings, append(turns, questionContent), opts)
+	if err != nil {
+		// Keep whatever arrived before the stream stopped, but don't run commands from a partial answer
+		partial := result != nil && result.text != ""
+		if partial {
+			recordExchange(sessionID, result.provider, settings.model, result.req.Model, question, attached, result.text, askedAt, nil)
+		}
+		return streamError(ctx, err, partial)
+	}
+
+	// Check for executable commands in the response (only run if enabled)
+	response := result.text
+	proposals := commandProposals(result.req, response, result.calls)
+	if strings.TrimSpace(response) == "" {
+		response = commands.FormatProposals(proposals)
+	}
+	records := handleCommands(proposals, opts.EnableCommands)
+
+	recordExchange(sessionID, result.provider, settings.model, result.req.Model, question, attached, response, askedAt, records)
+	return commandFailure(records)
+}
+
+// streamError explains why a response stopped, which is a cancellation when the user pressed Ctrl-C
+func streamError(ctx context.Context, err error, partial bool) error {
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("failed to generate content: %w", err)
+	}
+	if partial {
+		return types.NewError(types.ErrorCancelled, errors.New("stopped, the partial response was kept"))
+	}
+	return types.NewError(types.ErrorCancelled, errors.New("stopped before a response arrived"))
+}
+
+// commandFailure returns an error for the first executed command that failed, if any
+func commandFailure(records []types.CommandRecord) error {
+	for _, record := range records {
+		if record.Executed && record.ExitCode != 0 {
+			return types.NewError(types.ErrorCommandFailed, fmt.Errorf("`%s` exited with status %d", record.Command, record.ExitCode))
+		}
+	}
+	return nil
+}
+
+// fitAttachments shrinks the attachments to the token budget and shows which ones are included
+func fitAttachments(ctx context.Context, p provider.Provider, question string, opts Options) ([]*attach.Attachment, error) {
+	budget, err := config.GetTokenBudget(opts.TokenBudget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token budget: %w", err)
+	}
+
+	attachments, dropped, err := attach.Fit(question, opts.Attachments, budget, func(text string) (int, error) {
+		return p.CountTokens(ctx, opts.Model, genai.Text(text))
+	})
+	var overBudget *attach.OverBudgetError
+	switch {
+	case errors.As(err, &overBudget):
+		ui.ShowExecutionStatus(fmt.Sprintf("The question and attachments still use %d tokens after shrinking, more than the budget of %d", overBudget.Tokens, overBudget.Budget), "warning")
+	case err != nil:
+		// Counting is best effort, send everything rather than failing the question
+		ui.ShowExecutionStatus("Could not count tokens: "+err.Error(), "warning")
+	}
+
+	ui.ShowAttachments(attachments, opts.Images, dropped)
+	return attachments, nil
+}
+
+// attachmentRecords describes the attachments and images sent with a question for the history
+func attachmentRecords(attachments []*attach.Attachment, images []*attach.Image) []types.AttachmentRecord {
+	var records []types.AttachmentRecord
+	for _, a := range attachments {
+		records = append(records, types.AttachmentRecord{Name: a.Name, Bytes: len(a.Content)})
+	}
+	for _, image := range images {
+		records = append(records, types.AttachmentRecord{Name: image.Name, Bytes: len(image.Data)})
+	}
+	return records
+}
+
+// newQuestionContent creates the user turn for a question, with any images as inline parts
+func newQuestionContent(question string, images []*attach.Image) *genai.Content {
+	parts := []*genai.Part{genai.NewPartFromText(question)}
+	for _, image := range images {
+		parts = append(parts, genai.NewPartFromBytes(image.Data, image.MIMEType))
+	}
+	return genai.NewContentFromParts(parts, genai.RoleUser)
+}
+
+// newRequest creates a completion request, declaring the command tool when commands can be run and the provider supports tools
+func newRequest(p provider.Provider, s *settings, contents []*genai.Content, execute bool) *provider.Request {
+	req := &provider.Request{
+		Model:        s.model,
+		SystemPrompt: s.prompt,
+		Contents:     contents,
+		Generation:   s.generation,
+	}
+	if execute && p.SupportsTools() {
+		req.SystemPrompt += commandToolPrompt
+		req.Tools = []*genai.FunctionDeclaration{commands.ProposeCommandsDeclaration()}
+	}
+	return req
+}
+
+// streamResponse streams a completion to the terminal, records its token usage and returns
+// the full response text and any function calls
+func streamResponse(ctx context.Context, p provider.Provider, s *settings, req *provider.Request) (string, []*genai.FunctionCall, error) {
+	if err := checkBudget(); err != nil {
+		return "", nil, err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
 
 	var fullResponse strings.Builder
+	var calls []*genai.FunctionCall
+	var tokens *types.Usage
 
-	for result, err := range client.Models.GenerateContentStream(
-		ctx,
-		model,
-		genai.Text(systemPrompt+"\n\nUser question: "+question),
-		&genai.GenerateContentConfig{
-			Temperature: genai.Ptr(float32(0.7)),
-		},
-	) {
+	// Show the response as it arrives. The header waits for the first chunk,
+	// so an attempt that fails outright leaves nothing behind for a fallback
+	started := false
+	start := func() {
+		if !started {
+			ui.StartResponseStream()
+			started = true
+		}
+	}
+
+	for chunk, err := range p.Stream(ctx, req) {
 		if err != nil {
-			ui.ShowError("Error generating content: " + err.Error())
-			return
+			if started {
+				ui.EndResponseStream()
+			}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = types.NewError(types.ErrorNetwork, fmt.Errorf("no complete response within %s: %w", s.timeout, err))
+			}
+			return fullResponse.String(), calls, provider.Classify(err)
+		}
+
+		start()
+		ui.StreamResponseText(chunk.Text)
+		fullResponse.WriteString(chunk.Text)
+		calls = append(calls, chunk.FunctionCalls...)
+		if chunk.Usage != nil {
+			tokens = chunk.Usage
 		}
+	}
+
+	start()
+	ui.EndResponseStream()
+	recordUsage(p, s, req, tokens)
+
+	return fullResponse.String(), calls, nil
+}
 
-		text := result.Text()
-		fullResponse.WriteString(text)
+// commandProposals returns the commands proposed through the tool, or scraped from the text when the tool wasn't offered
+func commandProposals(req *provider.Request, response string, calls []*genai.FunctionCall) []types.CommandProposal {
+	if len(req.Tools) == 0 {
+		return commands.ProposalsFromText(response)
 	}
 
-	fmt.Println() // Add a newline after streaming is complete
+	proposals, err := commands.ParseProposals(calls)
+	if err != nil {
+		ui.ShowExecutionStatus(err.Error()+", using commands found in the response instead", "warning")
+		return commands.ProposalsFromText(response)
+	}
+	return proposals
+}
 
-	// Always render the final response with fancy markdown formatting
-	if fullResponse.Len() > 0 {
-		ui.RenderFinalResponse(fullResponse.String())
+// handleCommands runs the proposed commands the user confirms if execute is set and records the outcome
+func handleCommands(proposals []types.CommandProposal, execute bool) []types.CommandRecord {
+	if len(proposals) == 0 {
+		return nil
 	}
 
-	// Check for executable commands in the response (only if enabled)
-	if enableCommands {
-		detectedCommands := commands.ExtractCommands(fullResponse.String())
-		if len(detectedCommands) > 0 {
-			commandsToExecute := commands.PromptToExecute(detectedCommands)
-			if len(commandsToExecute) > 0 {
-				commands.ExecuteCommands(commandsToExecute)
+	records := make([]types.CommandRecord, len(proposals))
+	for i, proposal := range proposals {
+		ui.ShowCommandDetected(proposal)
+		records[i] = types.CommandRecord{Command: proposal.Command}
+	}
+
+	if !execute {
+		return records
+	}
+
+	commandsToExecute := commands.PromptToExecute(proposals)
+	if len(commandsToExecute) > 0 {
+		for _, result := range commands.ExecuteCommands(commandsToExecute) {
+			for i := range records {
+				if records[i].Command == result.Command {
+					records[i] = result
+				}
 			}
 		}
 	}
+
+	return records
+}
+
+// recordExchange persists a question and its answer to the history store, along with the model
+// that answered it when a fallback stepped in
+func recordExchange(sessionID, providerName, askedModel, answeredModel, question string, attached []types.AttachmentRecord, response string, askedAt time.Time, records []types.CommandRecord) *types.HistoryEntry {
+	entry := &types.HistoryEntry{
+		SessionID: sessionID,
+		Provider:  providerName,
+		Question: types.Question{
+			Text:        question,
+			Model:       askedModel,
+			Timestamp:   askedAt.Unix(),
+			Attachments: attached,
+		},
+		Response: types.Response{
+			Text:      response,
+			Model:     answeredModel,
+			Timestamp: time.Now().Unix(),
+		},
+		Commands: records,
+	}
+
+	// History is a convenience, so failing to save it shouldn't fail the question
+	if err := history.Save(entry); err != nil {
+		ui.ShowExecutionStatus("Could not save history: "+err.Error(), "warning")
+	}
+
+	return entry
 }
@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simplyzetax/oracle/internal/attach"
+	"github.com/simplyzetax/oracle/internal/history"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+func TestHistoryKeepsAttachmentsOut(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ORACLE_PROVIDER", "")
+	t.Setenv("ORACLE_RECORD", "")
+
+	requests := 0
+	server := answerServer(t, &requests)
+
+	secret := &attach.Attachment{Name: ".env.local", Content: "API_TOKEN=very-secret\n"}
+	opts := Options{Provider: "openai", BaseURL: server.URL, Model: "gpt-4o-mini", NoContext: true, NoCache: true, Attachments: []*attach.Attachment{secret}}
+	if err := AskQuestion("Why won't this start?", opts); err != nil {
+		t.Fatalf("AskQuestion: %v", err)
+	}
+
+	entries, err := history.List()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("history.List = %d entries, %v, want the one exchange", len(entries), err)
+	}
+	question := entries[0].Question
+	if question.Text != "Why won't this start?" {
+		t.Errorf("question = %q, want it as asked, without the attachment", question.Text)
+	}
+	want := types.AttachmentRecord{Name: ".env.local", Bytes: len(secret.Content)}
+	if len(question.Attachments) != 1 || question.Attachments[0] != want {
+		t.Errorf("attachments = %+v, want %+v", question.Attachments, want)
+	}
+
+	dir, err := history.GetHistoryDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := os.ReadDir(dir)
+	for _, file := range files {
+		data, _ := os.ReadFile(filepath.Join(dir, file.Name()))
+		if strings.Contains(string(data), "very-secret") {
+			t.Errorf("%s contains the attachment's content", file.Name())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"google.golang.org/genai"
+)
+
+// answer is a response together with the provider and request that produced it
+type answer struct {
+	provider string
+	req      *provider.Request
+	text     string
+	calls    []*genai.FunctionCall
+}
+
+// askWithFallback streams a response from the configured model, and when it is rate limited,
+// unknown or down before anything was shown, from each of the fallback models in turn
+func askWithFallback(ctx context.Context, p provider.Provider, s *settings, contents []*genai.Content, opts Options) (*answer, error) {
+	fallbacks, err := config.GetFallbacks(p.Name())
+	if err != nil {
+		return nil, err
+	}
+	chain := append([]config.Fallback{{Provider: p.Name(), Model: s.model}}, fallbacks...)
+
+	var lastErr error
+	for i, candidate := range chain {
+		if i > 0 && candidate == chain[0] {
+			continue
+		}
+
+		next, hasNext := nextCandidate(chain, i)
+		cp, cs, err := fallbackAttempt(ctx, p, s, candidate, opts)
+		if err != nil {
+			switch {
+			case i > 0:
+				ui.ShowExecutionStatus(fmt.Sprintf("Skipping fallback %s/%s: %v", candidate.Provider, candidate.Model, err), "warning")
+			case hasNext:
+				ui.ShowExecutionStatus(fmt.Sprintf("Can't use %s/%s (%v), trying fallback %s/%s", candidate.Provider, candidate.Model, err, next.Provider, next.Model), "warning")
+			}
+			lastErr = err
+			continue
+		}
+
+		req := newRequest(cp, cs, contents, opts.EnableCommands)
+		text, calls, err := streamResponse(ctx, cp, cs, req)
+		result := &answer{provider: cp.Name(), req: req, text: text, calls: calls}
+
+		// Once something was shown, or the user gave up, another model can't take over
+		if err == nil || text != "" || len(calls) > 0 || ctx.Err() != nil || !provider.Unavailable(err) {
+			return result, err
+		}
+		if hasNext {
+			ui.ShowExecutionStatus(fmt.Sprintf("%s/%s can't answer (%v), trying fallback %s/%s", cp.Name(), cs.model, err, next.Provider, next.Model), "warning")
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// nextCandidate returns the candidate tried after chain[i], skipping repeats of the configured model
+func nextCandidate(chain []config.Fallback, i int) (config.Fallback, bool) {
+	for _, candidate := range chain[i+1:] {
+		if candidate != chain[0] {
+			return candidate, true
+		}
+	}
+	return config.Fallback{}, false
+}
+
+// fallbackAttempt returns the provider and settings to ask a candidate model with, reusing the
+// primary provider when the candidate belongs to it
+func fallbackAttempt(ctx context.Context, p provider.Provider, s *settings, candidate config.Fallback, opts Options) (provider.Provider, *settings, error) {
+	cp := p
+	if candidate.Provider != p.Name() {
+		// The API key and base URL flags belong to the primary provider
+		opts.Provider = candidate.Provider
+		opts.APIKey = ""
+		opts.BaseURL = ""
+		var err error
+		if cp, err = NewProvider(ctx, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cs := *s
+	if err := cs.useModel(cp, candidate.Model); err != nil {
+		return nil, nil, err
+	}
+	return cp, &cs, nil
+}
@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/catalog"
+	"github.com/simplyzetax/oracle/internal/provider"
+)
+
+// modelCheckTimeout bounds how long fetching the model list for validation may take
+const modelCheckTimeout = 10 * time.Second
+
+// checkModel fails when the provider's model list doesn't include model, suggesting similar names.
+// The check is best effort, so a model list that can't be fetched lets every model through
+func checkModel(ctx context.Context, p provider.Provider, model string) error {
+	ctx, cancel := context.WithTimeout(ctx, modelCheckTimeout)
+	defer cancel()
+
+	list, fresh, err := catalog.Models(ctx, p, false)
+	if err != nil || len(list.Models) == 0 || catalog.Find(list.Models, model) != nil {
+		return nil
+	}
+
+	// The cached list may predate the model, so check a fresh one before failing
+	if !fresh {
+		list, _, err = catalog.Models(ctx, p, true)
+		if err != nil || len(list.Models) == 0 || catalog.Find(list.Models, model) != nil {
+			return nil
+		}
+	}
+
+	message := fmt.Sprintf("unknown model %q for the %s provider", model, p.Name())
+	if suggestions := catalog.Suggest(list.Models, model); len(suggestions) > 0 {
+		message += ", did you mean " + strings.Join(suggestions, " or ") + "?"
+	} else {
+		message += "."
+	}
+	return fmt.Errorf("%s Run `oracle models` to see the available models", message)
+}
@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/environment"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// Verbosity levels controlling how long answers are
+const (
+	VerbosityBrief   = "brief"
+	VerbosityNormal  = "normal"
+	VerbosityVerbose = "verbose"
+)
+
+// defaultTemperature is used when neither the flags, the persona nor the config set one
+const defaultTemperature = 0.7
+
+// Simplified system prompt for commands
+const systemPrompt = `You are Oracle, an AI assistant that provides answers and executable shell commands.
+Format commands clearly using:
+- Code blocks with triple backticks for multi-line commands
+- Inline backticks for single commands
+- Prefix with $ for commands
+
+Explain what commands do before suggesting them. Avoid dangerous commands.`
+
+// verbosityPrompts tell the model how long its answers should be
+var verbosityPrompts = map[string]string{
+	VerbosityBrief:   "Keep responses concise. Again, keep the response length to a maximum of 3 sentences.",
+	VerbosityNormal:  "Answer with as much detail as the question needs, without padding.",
+	VerbosityVerbose: "Give a thorough, in-depth answer: explain the reasoning, cover edge cases and alternatives, and include examples where they help.",
+}
+
+// environmentPrompt introduces the environment details added to the system prompt
+const environmentPrompt = `
+
+The user is running Oracle in this environment. Suggest commands that work there, using its shell syntax and package manager:
+`
+
+// settings are the model, system prompt and generation parameters resolved from flags, the persona and the config
+type settings struct {
+	model      string
+	prompt     string
+	generation types.GenerationConfig
+	// requested are the generation parameters from the flags, persona and config, before defaults
+	requested types.GenerationConfig
+	// persona is the name of the selected persona, recorded with the token usage
+	persona string
+	// timeout limits each model request, zero means no limit
+	timeout time.Duration
+}
+
+// resolveSettings combines the flags, the selected persona and the config into request settings
+// and checks the generation parameters against what the provider accepts
+func resolveSettings(p provider.Provider, opts Options) (*settings, error) {
+	personaName, persona, err := config.GetPersona(opts.Persona)
+	if err != nil {
+		return nil, err
+	}
+
+	generation, err := config.GetGeneration()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &settings{model: opts.Model, persona: personaName, timeout: opts.Timeout}
+	verbosity := VerbosityBrief
+	base := systemPrompt
+
+	// The config's prompt file is the fallback, a persona's prompt and then the flag take precedence
+	promptFile, err := config.GetSystemPromptFile("")
+	if err != nil {
+		return nil, err
+	}
+
+	if persona != nil {
+		if s.model == "" {
+			s.model = persona.Model
+		}
+		if persona.Temperature != nil {
+			generation.Temperature = persona.Temperature
+		}
+		if persona.Verbosity != "" {
+			verbosity = persona.Verbosity
+		}
+		if persona.SystemPrompt != "" {
+			base = persona.SystemPrompt
+			promptFile = ""
+		}
+	}
+	if opts.SystemPromptFile != "" {
+		promptFile = opts.SystemPromptFile
+	}
+	if opts.Verbosity != "" {
+		verbosity = opts.Verbosity
+	}
+
+	if s.model == "" {
+		if s.model, err = config.GetModel(""); err != nil {
+			return nil, fmt.Errorf("failed to get model: %w", err)
+		}
+	}
+	if s.model == "" {
+		s.model = p.DefaultModel()
+	}
+	if s.model == "" {
+		return nil, types.NewError(types.ErrorUsage, fmt.Errorf("no model configured for the %s provider, pass -m or set Model in the config", p.Name()))
+	}
+
+	// Flags override the persona and config value by value rather than as a whole
+	s.requested = generation.Merge(opts.Generation)
+	if err := s.useModel(p, s.model); err != nil {
+		return nil, err
+	}
+
+	if promptFile != "" {
+		data, err := os.ReadFile(promptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read system prompt file: %w", err)
+		}
+		base = strings.TrimSpace(string(data))
+	}
+
+	lengthPrompt, ok := verbosityPrompts[verbosity]
+	if !ok {
+		return nil, fmt.Errorf("unknown verbosity %q (use %s, %s or %s)", verbosity, VerbosityBrief, VerbosityNormal, VerbosityVerbose)
+	}
+
+	// Cassettes are found by a hash of the request, so it must not depend on the machine, directory or git state
+	noContext := opts.NoContext || config.GetRecord(opts.Record) || p.Name() == provider.ReplayProvider
+	s.prompt, err = buildSystemPrompt(base+" "+lengthPrompt, noContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Debug {
+		ui.ShowDebugSettings(p.Name(), s.model, s.generation)
+	}
+	return s, nil
+}
+
+// useModel switches the settings to a model, checking the requested generation parameters against it
+// and adding the default temperature when the model accepts one
+func (s *settings) useModel(p provider.Provider, model string) error {
+	if err := p.ValidateGeneration(model, s.requested); err != nil {
+		return fmt.Errorf("invalid generation parameters: %w", err)
+	}
+
+	generation := s.requested
+	if generation.Temperature == nil {
+		withDefault := generation
+		withDefault.Temperature = genai.Ptr(float32(defaultTemperature))
+		// Models with a fixed temperature, like OpenAI's reasoning models, are left at theirs
+		if p.ValidateGeneration(model, withDefault) == nil {
+			generation = withDefault
+		}
+	}
+
+	s.model = model
+	s.generation = generation
+	return nil
+}
+
+// buildSystemPrompt adds details about the user's environment to the prompt unless disabled
+func buildSystemPrompt(prompt string, noContext bool) (string, error) {
+	probes, err := config.GetContextProbes(noContext)
+	if err != nil {
+		return "", err
+	}
+
+	details := environment.Describe(probes)
+	if details == "" {
+		return prompt, nil
+	}
+	return prompt + environmentPrompt + details, nil
+}
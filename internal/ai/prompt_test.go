@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+func TestResolveSettingsTemperature(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	p, err := provider.New(context.Background(), "openai", provider.Options{BaseURL: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("provider.New: %v", err)
+	}
+
+	s, err := resolveSettings(p, Options{Model: "gpt-4o-mini", NoContext: true})
+	if err != nil {
+		t.Fatalf("resolveSettings: %v", err)
+	}
+	if s.generation.Temperature == nil || *s.generation.Temperature != defaultTemperature {
+		t.Errorf("temperature = %v, want the default %g", s.generation.Temperature, defaultTemperature)
+	}
+
+	// Reasoning models keep their own temperature unless one is asked for
+	s, err = resolveSettings(p, Options{Model: "o3-mini", NoContext: true})
+	if err != nil {
+		t.Fatalf("resolveSettings: %v", err)
+	}
+	if s.generation.Temperature != nil {
+		t.Errorf("temperature = %g, want none for a reasoning model", *s.generation.Temperature)
+	}
+
+	explicit := types.GenerationConfig{Temperature: genai.Ptr(float32(0.2))}
+	if _, err := resolveSettings(p, Options{Model: "o3-mini", NoContext: true, Generation: explicit}); err == nil {
+		t.Error("resolveSettings accepted an explicit temperature for a reasoning model")
+	}
+}
+
+func TestResolveSettingsDefaultModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+
+	p, err := provider.New(ctx, "openai", provider.Options{BaseURL: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("provider.New: %v", err)
+	}
+	s, err := resolveSettings(p, Options{NoContext: true})
+	if err != nil {
+		t.Fatalf("resolveSettings: %v", err)
+	}
+	if s.model != p.DefaultModel() {
+		t.Errorf("model = %q, want the openai default %q", s.model, p.DefaultModel())
+	}
+
+	// Ollama has no default, so the user has to pick a model
+	p, err = provider.New(ctx, "ollama", provider.Options{BaseURL: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("provider.New: %v", err)
+	}
+	_, err = resolveSettings(p, Options{NoContext: true})
+	if kind := types.KindOf(err); kind != types.ErrorUsage {
+		t.Errorf("err = %v (kind %q), want a %q error", err, kind, types.ErrorUsage)
+	}
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simplyzetax/oracle/internal/provider"
+	"google.golang.org/genai"
+)
+
+// askOnce builds the request for a question the way AskQuestion does and returns the streamed text
+func askOnce(t *testing.T, opts Options, question string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	p, err := NewProvider(ctx, opts)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	s, err := resolveSettings(p, opts)
+	if err != nil {
+		t.Fatalf("resolveSettings: %v", err)
+	}
+	req := newRequest(p, s, []*genai.Content{genai.NewContentFromText(question, genai.RoleUser)}, false)
+
+	var text strings.Builder
+	for chunk, err := range p.Stream(ctx, req) {
+		if err != nil {
+			t.Fatalf("Stream with %s: %v", p.Name(), err)
+		}
+		text.WriteString(chunk.Text)
+	}
+	return text.String()
+}
+
+// askCached asks a question through the response cache the way AskQuestion does and returns the answer
+func askCached(t *testing.T, opts Options, question string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	p, err := NewProvider(ctx, opts)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	s, err := resolveSettings(p, opts)
+	if err != nil {
+		t.Fatalf("resolveSettings: %v", err)
+	}
+	result, err := cachedResponse(ctx, p, s, []*genai.Content{genai.NewContentFromText(question, genai.RoleUser)}, opts)
+	if err != nil {
+		t.Fatalf("cachedResponse with %s: %v", p.Name(), err)
+	}
+	return result.text
+}
+
+// answerServer streams the same answer to every request and counts them
+func answerServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{"Use ", "docker image prune."} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", part)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRecordThenReplayInAnotherEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ORACLE_CASSETTE_DIR", t.TempDir())
+	t.Setenv("ORACLE_PROVIDER", "")
+	t.Setenv("ORACLE_RECORD", "")
+
+	requests := 0
+	server := answerServer(t, &requests)
+
+	// Record in a Go project on one "machine"
+	recordDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(recordDir, "go.mod"), []byte("module example.com/recorded\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(recordDir)
+	recorded := askOnce(t, Options{Provider: "openai", BaseURL: server.URL, Model: "gpt-4o-mini", Record: true}, "Clean up old docker images")
+
+	// Replay from an unrelated directory, as CI would
+	t.Chdir(t.TempDir())
+	replayed := askOnce(t, Options{Provider: provider.ReplayProvider, Model: "gpt-4o-mini"}, "Clean up old docker images")
+
+	if recorded != "Use docker image prune." {
+		t.Errorf("recorded %q, want the server's answer", recorded)
+	}
+	if replayed != recorded {
+		t.Errorf("replayed %q, want the recorded %q", replayed, recorded)
+	}
+	if requests != 1 {
+		t.Errorf("server got %d requests, want only the recorded one", requests)
+	}
+}
+
+func TestRecordWithWarmCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ORACLE_CASSETTE_DIR", t.TempDir())
+	t.Setenv("ORACLE_PROVIDER", "")
+	t.Setenv("ORACLE_RECORD", "")
+
+	requests := 0
+	server := answerServer(t, &requests)
+	asked := Options{Provider: "openai", BaseURL: server.URL, Model: "gpt-4o-mini", NoContext: true}
+
+	// The first answer fills the response cache for the same request the recording makes
+	askCached(t, asked, "Clean up old docker images")
+	recording := asked
+	recording.Record = true
+	recorded := askCached(t, recording, "Clean up old docker images")
+	if requests != 2 {
+		t.Errorf("server got %d requests, want the recording to bypass the cache", requests)
+	}
+
+	replayed := askCached(t, Options{Provider: provider.ReplayProvider, Model: "gpt-4o-mini", NoCache: true}, "Clean up old docker images")
+	if replayed != recorded {
+		t.Errorf("replayed %q, want the recorded %q", replayed, recorded)
+	}
+}
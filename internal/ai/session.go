@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/simplyzetax/oracle/internal/catalog"
+	"github.com/simplyzetax/oracle/internal/history"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"google.golang.org/genai"
+)
+
+// loadSession resolves the session to use and rebuilds the conversation of a continued session
+func loadSession(opts Options) (string, []*genai.Content, error) {
+	sessionID := opts.SessionID
+	if sessionID == "" && opts.Continue {
+		latest, err := history.LatestSessionID()
+		if err != nil {
+			return "", nil, err
+		}
+		sessionID = latest
+	}
+
+	// Nothing to continue, start a new session
+	if sessionID == "" {
+		return history.NewID(), nil, nil
+	}
+
+	entries, err := history.Session(sessionID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	contents := make([]*genai.Content, 0, len(entries)*2)
+	for _, entry := range entries {
+		contents = append(contents,
+			genai.NewContentFromText(entry.Question.Text, genai.RoleUser),
+			genai.NewContentFromText(entry.Response.Text, genai.RoleModel),
+		)
+	}
+
+	return sessionID, contents, nil
+}
+
+// trimHistory drops the oldest turns until the conversation and question fit the model's context window
+func trimHistory(ctx context.Context, p provider.Provider, model string, turns []*genai.Content, question *genai.Content) ([]*genai.Content, int) {
+	if len(turns) == 0 {
+		return turns, 0
+	}
+
+	// Leave a quarter of the window for the system prompt and the answer
+	budget := catalog.ContextWindow(ctx, p, model) * 3 / 4
+
+	dropped := 0
+	for len(turns) > 0 {
+		tokens, err := p.CountTokens(ctx, model, append(append([]*genai.Content{}, turns...), question))
+		if err != nil || tokens <= budget {
+			break
+		}
+		// Drop the oldest question and answer together
+		turns = turns[2:]
+		dropped++
+	}
+
+	return turns, dropped
+}
+
+// describeSession returns a short status line about a continued session
+func describeSession(sessionID string, turns, dropped int) string {
+	msg := fmt.Sprintf("Continuing session %s with %d previous turn(s)", sessionID, turns)
+	if dropped > 0 {
+		msg += fmt.Sprintf(", %d older turn(s) dropped to fit the context window", dropped)
+	}
+	return msg
+}
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/provider"
+	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/internal/usage"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// checkBudget fails when a token budget is used up, or only warns when the budget is set to warn
+func checkBudget() error {
+	budget, err := config.GetBudget()
+	if err != nil {
+		return err
+	}
+	if budget.DailyTokens <= 0 && budget.MonthlyTokens <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	records, err := usage.Load(usage.StartOfMonth(now))
+	if err != nil {
+		return err
+	}
+
+	exceeded := usage.Exceeded(budget, records, now)
+	if exceeded == "" {
+		return nil
+	}
+	if budget.Action == types.BudgetWarn {
+		ui.ShowExecutionStatus("Over budget: "+exceeded, "warning")
+		return nil
+	}
+	return types.NewError(types.ErrorQuota, fmt.Errorf("%s, raise Budget in the config or set its Action to %q to keep asking", exceeded, types.BudgetWarn))
+}
+
+// recordUsage shows which model answered and the tokens it used, and adds them to the usage ledger
+func recordUsage(p provider.Provider, s *settings, req *provider.Request, tokens *types.Usage) {
+	if tokens == nil {
+		ui.ShowUsage(p.Name(), req.Model, nil, 0, false)
+		return
+	}
+
+	// Prices only affect the estimate, so a broken price table shouldn't hide the usage
+	prices, _ := config.GetPrices()
+	price, priced := usage.PriceFor(req.Model, prices)
+	ui.ShowUsage(p.Name(), req.Model, tokens, usage.Cost(*tokens, price), priced)
+
+	record := types.UsageRecord{
+		Timestamp: time.Now().Unix(),
+		Provider:  p.Name(),
+		Model:     req.Model,
+		Persona:   s.persona,
+		Usage:     *tokens,
+	}
+	if err := usage.Record(record); err != nil {
+		ui.ShowExecutionStatus("Could not record usage: "+err.Error(), "warning")
+	}
+}
@@ -0,0 +1,81 @@
+package attach
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultStdinLimit is the maximum number of bytes read from piped standard input
+const DefaultStdinLimit = 256 * 1024
+
+// Attachment is a piece of context sent along with the question
+type Attachment struct {
+	Name      string
+	Content   string
+	Truncated bool
+}
+
+// StdinPiped reports whether standard input is a pipe or a file, which end, unlike the
+// sockets and devices cron, CI runners and ssh -T can leave it connected to
+func StdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular() || info.Mode()&os.ModeNamedPipe != 0
+}
+
+// FromStdin reads piped standard input, keeping at most limit bytes
+func FromStdin(limit int) (*Attachment, error) {
+	return fromReader("stdin", os.Stdin, limit)
+}
+
+// fromReader reads at most limit bytes from r into an attachment
+func fromReader(name string, r io.Reader, limit int) (*Attachment, error) {
+	// Read one extra byte to detect whether the input was cut off
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	attachment := &Attachment{Name: name}
+	if len(data) > limit {
+		data = trimToValidUTF8(data[:limit])
+		attachment.Truncated = true
+	}
+	attachment.Content = string(data)
+
+	return attachment, nil
+}
+
+// Format appends the attachments to the question as clearly delimited context blocks
+func Format(question string, attachments []*Attachment) string {
+	if len(attachments) == 0 {
+		return question
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(question)
+
+	for _, a := range attachments {
+		fmt.Fprintf(&prompt, "\n\n----- BEGIN %s -----\n", a.Name)
+		prompt.WriteString(strings.TrimRight(a.Content, "\n"))
+		if a.Truncated {
+			fmt.Fprintf(&prompt, "\n[... truncated, only the first %d bytes are shown ...]", len(a.Content))
+		}
+		fmt.Fprintf(&prompt, "\n----- END %s -----", a.Name)
+	}
+
+	return prompt.String()
+}
+
+// trimToValidUTF8 drops a partial multi-byte character left at the end of data by truncation
+func trimToValidUTF8(data []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return data
+}
@@ -0,0 +1,87 @@
+package attach
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultTokenBudget is the default number of tokens the question and attachments may use
+	DefaultTokenBudget = 32000
+	// minKeepBytes is the smallest truncated attachment worth sending, smaller ones are dropped
+	minKeepBytes = 512
+	// maxFitAttempts bounds how many times the token count is rechecked while shrinking
+	maxFitAttempts = 8
+)
+
+// OverBudgetError reports that the question and attachments still exceed the budget after shrinking
+type OverBudgetError struct {
+	Tokens int
+	Budget int
+}
+
+// Error implements the error interface
+func (e *OverBudgetError) Error() string {
+	return fmt.Sprintf("the question and attachments use %d tokens, more than the budget of %d", e.Tokens, e.Budget)
+}
+
+// TokenCounter returns the number of tokens text uses with the current model
+type TokenCounter func(text string) (int, error)
+
+// Fit truncates the largest attachments until the question and attachments fit within budget tokens.
+// It returns the attachments to send and the names of those dropped entirely, with an
+// *OverBudgetError when they still don't fit.
+func Fit(question string, attachments []*Attachment, budget int, count TokenCounter) ([]*Attachment, []string, error) {
+	if budget <= 0 || len(attachments) == 0 {
+		return attachments, nil, nil
+	}
+
+	var dropped []string
+	for attempt := 0; ; attempt++ {
+		prompt := Format(question, attachments)
+		tokens, err := count(prompt)
+		if err != nil {
+			return attachments, dropped, err
+		}
+		if tokens <= budget {
+			return attachments, dropped, nil
+		}
+		if attempt == maxFitAttempts || len(attachments) == 0 {
+			return attachments, dropped, &OverBudgetError{Tokens: tokens, Budget: budget}
+		}
+
+		// Estimate how many bytes to cut from the observed bytes per token
+		bytesPerToken := float64(len(prompt)) / float64(tokens)
+		excess := int(float64(tokens-budget)*bytesPerToken) + 1
+
+		largest := 0
+		for i, a := range attachments {
+			if len(a.Content) > len(attachments[largest].Content) {
+				largest = i
+			}
+		}
+
+		a := attachments[largest]
+		keep := len(a.Content) - excess
+		if keep < minKeepBytes {
+			dropped = append(dropped, a.Name)
+			attachments = append(attachments[:largest:largest], attachments[largest+1:]...)
+			continue
+		}
+
+		a.Content = truncateAtLine(a.Content, keep)
+		a.Truncated = true
+	}
+}
+
+// truncateAtLine cuts text to at most n bytes, preferring to end at a line break
+func truncateAtLine(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	text = text[:n]
+	if idx := strings.LastIndexByte(text, '\n'); idx > n/2 {
+		text = text[:idx]
+	}
+	return string(trimToValidUTF8([]byte(text)))
+}
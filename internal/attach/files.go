@@ -0,0 +1,233 @@
+package attach
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxFileSize is the largest amount of a single file that is read for attachment
+	MaxFileSize = 1024 * 1024
+	// MaxDirFiles is the largest number of files attached from a single directory
+	MaxDirFiles = 200
+	// binarySniffLen is how much of a file is inspected to detect binary content
+	binarySniffLen = 8000
+)
+
+// Skipped describes a file that was not attached and why
+type Skipped struct {
+	Path   string
+	Reason string
+}
+
+// Collect resolves file patterns and directories into attachments, skipping binary and git-ignored files
+func Collect(patterns, dirs []string) ([]*Attachment, []Skipped, error) {
+	var attachments []*Attachment
+	var skipped []Skipped
+	seen := make(map[string]bool)
+
+	add := func(file string) error {
+		file = filepath.Clean(file)
+		if seen[file] {
+			return nil
+		}
+		seen[file] = true
+
+		attachment, reason, err := fromFile(file)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			skipped = append(skipped, Skipped{Path: file, Reason: reason})
+			return nil
+		}
+		attachments = append(attachments, attachment)
+		return nil
+	}
+
+	for _, pattern := range patterns {
+		files, err := expandPattern(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range files {
+			if err := add(file); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		files, err := walkDir(dir, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(files) > MaxDirFiles {
+			for _, file := range files[MaxDirFiles:] {
+				skipped = append(skipped, Skipped{Path: file, Reason: fmt.Sprintf("more than %d files in %s", MaxDirFiles, dir)})
+			}
+			files = files[:MaxDirFiles]
+		}
+		for _, file := range files {
+			if err := add(file); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return attachments, skipped, nil
+}
+
+// expandPattern resolves a path or glob, including ** patterns, into file paths
+func expandPattern(pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+
+	var files []string
+	if strings.Contains(pattern, "**") {
+		// Walk from the part of the pattern before the first wildcard
+		root := filepath.Dir(pattern[:strings.IndexAny(pattern, "*?[")] + "x")
+		re, err := regexp.Compile("^" + globToRegexp(filepath.ToSlash(filepath.Clean(pattern))) + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		files, err = walkDir(root, re)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, match)
+			}
+		}
+		files = withoutIgnored(files)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %q", pattern)
+	}
+	return files, nil
+}
+
+// withoutIgnored drops the files that the .gitignore files of their repository ignore
+func withoutIgnored(files []string) []string {
+	matchers := make(map[string]*ignoreMatcher)
+	var kept []string
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			kept = append(kept, file)
+			continue
+		}
+
+		root := repoRoot(filepath.Dir(abs))
+		matcher := matchers[root]
+		if matcher == nil {
+			matcher = newIgnoreMatcher(root)
+			matchers[root] = matcher
+		}
+		if rel, ok := matcher.rel(abs); ok && matcher.ignoredPath(rel, false) {
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept
+}
+
+// walkDir lists the files under root that aren't git-ignored, optionally filtered by a path pattern.
+// The .gitignore files of the directories above root count too, up to the repository root
+func walkDir(root string, filter *regexp.Regexp) ([]string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", root, err)
+	}
+	matcher := newIgnoreMatcher(repoRoot(absRoot))
+	base, _ := matcher.rel(absRoot)
+	var files []string
+
+	err = filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		rel = path.Join(base, filepath.ToSlash(rel))
+
+		if d.IsDir() {
+			// root itself is walked even when ignored, since it was asked for by name
+			if d.Name() == ".git" || (file != root && matcher.ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			matcher.loadDirs(rel)
+			return nil
+		}
+
+		if !d.Type().IsRegular() || matcher.ignored(rel, false) {
+			return nil
+		}
+		if filter != nil && !filter.MatchString(filepath.ToSlash(filepath.Clean(file))) {
+			return nil
+		}
+
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", root, err)
+	}
+
+	return files, nil
+}
+
+// fromFile reads a file into an attachment, or returns why it was skipped
+func fromFile(file string) (*Attachment, string, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if info.IsDir() {
+		return nil, "is a directory, use --dir", nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer f.Close()
+
+	attachment, err := fromReader(file, f, MaxFileSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if isBinary([]byte(attachment.Content)) {
+		return nil, "binary file", nil
+	}
+
+	return attachment, "", nil
+}
+
+// isBinary reports whether data looks like binary rather than text
+func isBinary(data []byte) bool {
+	sample := data[:min(len(data), binarySniffLen)]
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	return !utf8.Valid(trimToValidUTF8(sample))
+}
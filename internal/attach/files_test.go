@@ -0,0 +1,112 @@
+package attach
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files with the given contents under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// names returns the sorted names of attachments
+func names(attachments []*Attachment) []string {
+	var result []string
+	for _, a := range attachments {
+		result = append(result, filepath.ToSlash(a.Name))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestCollectHonorsGitignore(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, repo, map[string]string{
+		".gitignore":             "*.log\n",
+		"web/.gitignore":         "dist/\n",
+		"web/dist/app.js":        "built",
+		"web/dist/app.js.map":    "built",
+		"web/src/main.js":        "source",
+		"web/src/debug.log":      "noise",
+		"web/src/keep/.gitkeep":  "",
+		"web/src/keep/notes.txt": "notes",
+	})
+	t.Chdir(filepath.Join(repo, "web"))
+
+	tests := []struct {
+		name     string
+		patterns []string
+		dirs     []string
+		want     []string
+	}{
+		{"plain glob skips ignored files", []string{"src/*"}, nil, []string{"src/main.js"}},
+		{"plain glob skips ignored directories", []string{"dist/*"}, nil, nil},
+		{"double star glob", []string{"**/*.js"}, nil, []string{"src/main.js"}},
+		{"directory below the repository root", nil, []string{"src"}, []string{"src/keep/.gitkeep", "src/keep/notes.txt", "src/main.js"}},
+		{"explicit path", []string{"src/debug.log"}, nil, []string{"src/debug.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachments, _, err := Collect(tt.patterns, tt.dirs)
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("Collect attached %v, want no matching files", names(attachments))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Collect: %v", err)
+			}
+			if got := names(attachments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("attached %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitReportsOverBudget(t *testing.T) {
+	// The question alone is over the budget, so not even dropping the attachment makes it fit
+	count := func(text string) (int, error) {
+		return len(text), nil
+	}
+	attachments := []*Attachment{{Name: "big.txt", Content: strings.Repeat("x\n", 2000)}}
+
+	kept, dropped, err := Fit(strings.Repeat("why? ", 100), attachments, 100, count)
+	var overBudget *OverBudgetError
+	if !errors.As(err, &overBudget) {
+		t.Fatalf("err = %v, want an OverBudgetError", err)
+	}
+	if overBudget.Budget != 100 || overBudget.Tokens <= 100 {
+		t.Errorf("OverBudgetError = %+v, want the tokens still over the budget of 100", overBudget)
+	}
+	if len(kept) != 0 || !reflect.DeepEqual(dropped, []string{"big.txt"}) {
+		t.Errorf("kept %v and dropped %v, want big.txt dropped", names(kept), dropped)
+	}
+
+	attachments = []*Attachment{{Name: "small.txt", Content: strings.Repeat("line\n", 400)}}
+	kept, _, err = Fit("why?", attachments, 1000, count)
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if tokens, _ := count(Format("why?", kept)); tokens > 1000 {
+		t.Errorf("fitted prompt uses %d tokens, want at most 1000", tokens)
+	}
+}
@@ -0,0 +1,195 @@
+package attach
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a single pattern from a .gitignore file
+type ignoreRule struct {
+	base    string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	// basename rules have no slash and match a name at any depth
+	basename bool
+}
+
+// ignoreMatcher applies the .gitignore rules of a repository, loading each directory's file as it is needed
+type ignoreMatcher struct {
+	root   string
+	rules  []ignoreRule
+	loaded map[string]bool
+}
+
+// newIgnoreMatcher creates a matcher for paths relative to root, normally a repository root
+func newIgnoreMatcher(root string) *ignoreMatcher {
+	return &ignoreMatcher{root: root, loaded: make(map[string]bool)}
+}
+
+// repoRoot returns the nearest directory at or above dir that holds a .git entry, or dir itself
+// when it isn't inside a repository
+func repoRoot(dir string) string {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// rel returns the slash-separated path of an absolute path relative to the root
+func (m *ignoreMatcher) rel(absPath string) (string, bool) {
+	rel, err := filepath.Rel(m.root, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// loadDirs reads the .gitignore files of relDir and every directory above it up to the root,
+// shallowest first so deeper rules take precedence
+func (m *ignoreMatcher) loadDirs(relDir string) {
+	m.load(".")
+	if relDir == "." || relDir == "" {
+		return
+	}
+	parts := strings.Split(relDir, "/")
+	for i := range parts {
+		m.load(strings.Join(parts[:i+1], "/"))
+	}
+}
+
+// ignoredPath reports whether a path relative to the root, or any directory above it, is ignored
+func (m *ignoreMatcher) ignoredPath(relPath string, isDir bool) bool {
+	dir := path.Dir(relPath)
+	m.loadDirs(dir)
+	if dir != "." {
+		parts := strings.Split(dir, "/")
+		for i := range parts {
+			if m.ignored(strings.Join(parts[:i+1], "/"), true) {
+				return true
+			}
+		}
+	}
+	return m.ignored(relPath, isDir)
+}
+
+// load reads the .gitignore file in relDir, if there is one and it wasn't read before
+func (m *ignoreMatcher) load(relDir string) {
+	if m.loaded[relDir] {
+		return
+	}
+	m.loaded[relDir] = true
+
+	file, err := os.Open(filepath.Join(m.root, relDir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: filepath.ToSlash(relDir)}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		rule.basename = !strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		pattern, err := regexp.Compile("^" + globToRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		rule.pattern = pattern
+		m.rules = append(m.rules, rule)
+	}
+}
+
+// ignored reports whether the slash-separated path relative to root is ignored
+func (m *ignoreMatcher) ignored(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		rel := relPath
+		if rule.base != "." && rule.base != "" {
+			if !strings.HasPrefix(relPath, rule.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(relPath, rule.base+"/")
+		}
+
+		target := rel
+		if rule.basename {
+			target = path.Base(rel)
+		}
+
+		// The last matching rule wins, so negations can re-include paths
+		if rule.pattern.MatchString(target) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp converts a gitignore-style glob into a regular expression
+func globToRegexp(glob string) string {
+	var re strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**"):
+			re.WriteString("(?:/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end == -1 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(glob):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return re.String()
+}
@@ -0,0 +1,65 @@
+package attach
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// MaxImageSize is the largest image that is sent inline with a question
+const MaxImageSize = 20 * 1024 * 1024
+
+// imageTypes are the image formats vision models accept inline
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/webp": true,
+}
+
+// Image is an image sent inline along with the question
+type Image struct {
+	Name     string
+	MIMEType string
+	Data     []byte
+}
+
+// LoadImages reads the given image files, checking their size and format
+func LoadImages(paths []string) ([]*Image, error) {
+	images := make([]*Image, 0, len(paths))
+	for _, path := range paths {
+		image, err := loadImage(path)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// loadImage reads a single image file and detects its type from its contents
+func loadImage(path string) (*Image, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, not an image", path)
+	}
+	if info.Size() > MaxImageSize {
+		return nil, fmt.Errorf("%s is %.1f MB, images can be at most %d MB", path, float64(info.Size())/(1024*1024), MaxImageSize/(1024*1024))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	// The extension can lie, so sniff the actual format
+	mimeType := http.DetectContentType(data)
+	if !imageTypes[mimeType] {
+		return nil, fmt.Errorf("%s is not a PNG, JPEG or WebP image (detected %s)", path, mimeType)
+	}
+
+	return &Image{Name: filepath.Base(path), MIMEType: mimeType, Data: data}, nil
+}
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"google.golang.org/genai"
+)
+
+// Entry is a cached response and the provider and model that gave it
+type Entry struct {
+	Key           string
+	Provider      string
+	Model         string
+	Response      string
+	FunctionCalls []*genai.FunctionCall
+	// NoTools is set when the model that answered wasn't offered the command tool
+	NoTools   bool
+	CreatedAt int64
+}
+
+// Stats describes what the cache holds
+type Stats struct {
+	Entries int
+	Expired int
+	Size    int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// GetCacheDir returns the directory cached responses are stored in
+func GetCacheDir() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir := filepath.Join(configDir, "cache")
+
+	// Responses can contain sensitive output, so keep them private like the history
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return "", err
+	}
+
+	return cacheDir, nil
+}
+
+// Key hashes everything that determines a response into a cache key
+func Key(request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the entry stored under key if it is younger than ttl, removing it once it has expired
+func Get(key string, ttl time.Duration) (*Entry, bool) {
+	path, err := entryPath(key)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		// A damaged entry is a miss, and is replaced by the next response
+		return nil, false
+	}
+
+	if time.Since(time.Unix(entry.CreatedAt, 0)) > ttl {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Put stores an entry, then shrinks the cache to maxSize by removing expired and then the oldest entries
+func Put(entry *Entry, ttl time.Duration, maxSize int64) error {
+	path, err := entryPath(entry.Key)
+	if err != nil {
+		return fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	if entry.CreatedAt == 0 {
+		entry.CreatedAt = time.Now().Unix()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return prune(ttl, maxSize)
+}
+
+// Stat returns the number and size of the cached entries and how many of them have expired
+func Stat(ttl time.Duration) (*Stats, error) {
+	files, err := list()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{Entries: len(files)}
+	for _, file := range files {
+		stats.Size += file.Size()
+		modified := file.ModTime()
+		if time.Since(modified) > ttl {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || modified.Before(stats.Oldest) {
+			stats.Oldest = modified
+		}
+		if modified.After(stats.Newest) {
+			stats.Newest = modified
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes all cached entries and returns how many were removed
+func Clear() (int, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	files, err := list()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, file := range files {
+		if err := os.Remove(filepath.Join(cacheDir, file.Name())); err != nil && !os.IsNotExist(err) {
+			return i, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+	return len(files), nil
+}
+
+// prune removes expired entries, then the oldest ones until the cache fits in maxSize
+func prune(ttl time.Duration, maxSize int64) error {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	files, err := list()
+	if err != nil {
+		return err
+	}
+
+	// Oldest first, entries are written once so their modification time is their age
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	var size int64
+	for _, file := range files {
+		size += file.Size()
+	}
+
+	for _, file := range files {
+		if size <= maxSize && time.Since(file.ModTime()) <= ttl {
+			continue
+		}
+		if err := os.Remove(filepath.Join(cacheDir, file.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		size -= file.Size()
+	}
+	return nil
+}
+
+// list returns the cache entry files
+func list() ([]os.FileInfo, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache: %w", err)
+	}
+
+	files := make([]os.FileInfo, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+	}
+	return files, nil
+}
+
+// entryPath returns the file an entry with the given key is stored in
+func entryPath(key string) (string, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, key+".json"), nil
+}
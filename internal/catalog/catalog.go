@@ -0,0 +1,196 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/internal/provider"
+)
+
+// TTL is how long a provider's model list is reused before it is fetched again
+const TTL = 24 * time.Hour
+
+// maxSuggestions is how many similar model names are suggested for an unknown model
+const maxSuggestions = 3
+
+// List is a provider endpoint's models as fetched at a point in time
+type List struct {
+	FetchedAt int64
+	Models    []provider.Model
+}
+
+// GetCachePath returns the file model lists are cached in
+func GetCachePath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "models.json"), nil
+}
+
+// Models returns the provider's models, from the cache unless it is stale or refresh is set.
+// fresh reports whether the list was just fetched
+func Models(ctx context.Context, p provider.Provider, refresh bool) (list *List, fresh bool, err error) {
+	lists, err := load()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cached, ok := lists[key(p)]; ok && !refresh && time.Since(time.Unix(cached.FetchedAt, 0)) < TTL {
+		return cached, false, nil
+	}
+
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Name < models[j].Name
+	})
+
+	list = &List{FetchedAt: time.Now().Unix(), Models: models}
+	lists[key(p)] = list
+	if err := save(lists); err != nil {
+		return nil, false, err
+	}
+	return list, true, nil
+}
+
+// Cached returns the cached models of a provider without fetching them, even when stale
+func Cached(p provider.Provider) []provider.Model {
+	lists, err := load()
+	if err != nil || lists[key(p)] == nil {
+		return nil
+	}
+	return lists[key(p)].Models
+}
+
+// ContextWindow returns the input token limit of a model from the cached list, falling back
+// to provider.DefaultContextWindow when the model or its limit is unknown
+func ContextWindow(ctx context.Context, p provider.Provider, model string) int {
+	list, _, err := Models(ctx, p, false)
+	if err != nil {
+		return provider.DefaultContextWindow
+	}
+	if m := Find(list.Models, model); m != nil && m.InputTokenLimit > 0 {
+		return m.InputTokenLimit
+	}
+	return provider.DefaultContextWindow
+}
+
+// key identifies a provider endpoint in the cache, since two endpoints of a provider serve different models
+func key(p provider.Provider) string {
+	return p.Name() + " " + p.Endpoint()
+}
+
+// Find returns the model called name, accepting Gemini's models/ prefix and Ollama's implicit :latest tag
+func Find(models []provider.Model, name string) *provider.Model {
+	name = strings.TrimPrefix(name, "models/")
+	for i, m := range models {
+		if m.Name == name || m.Name == name+":latest" {
+			return &models[i]
+		}
+	}
+	return nil
+}
+
+// Suggest returns the model names closest to name, for "did you mean" hints
+func Suggest(models []provider.Model, name string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	// Allow roughly one typo per four characters, and always suggest names containing the input
+	limit := max(len(name)/4, 2)
+	var candidates []candidate
+	for _, m := range models {
+		distance := levenshtein(strings.ToLower(name), strings.ToLower(m.Name))
+		if distance <= limit || strings.Contains(m.Name, name) {
+			candidates = append(candidates, candidate{m.Name, distance})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var names []string
+	for _, c := range candidates {
+		if len(names) == maxSuggestions {
+			break
+		}
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// load reads the cached model lists, keyed by provider and endpoint
+func load() (map[string]*List, error) {
+	path, err := GetCachePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model cache path: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(map[string]*List), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model cache: %w", err)
+	}
+
+	lists := make(map[string]*List)
+	if err := json.Unmarshal(data, &lists); err != nil {
+		// A damaged cache is rebuilt on the next fetch
+		return make(map[string]*List), nil
+	}
+	return lists, nil
+}
+
+// save writes the cached model lists
+func save(lists map[string]*List) error {
+	path, err := GetCachePath()
+	if err != nil {
+		return fmt.Errorf("failed to get model cache path: %w", err)
+	}
+
+	data, err := json.MarshalIndent(lists, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal model cache: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write model cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package commands
+
+// MaxCapturedOutput is how much of each output stream is kept when a command's output is captured
+const MaxCapturedOutput = 16 * 1024
+
+// CapturedOutput is the output of a command that is sent back to the model
+type CapturedOutput struct {
+	Stdout    string
+	Stderr    string
+	Truncated bool
+}
+
+// tailBuffer keeps the last limit bytes written to it, since errors usually show up at the end
+type tailBuffer struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+// Write appends p, discarding the oldest bytes beyond the limit
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.limit; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// String returns the kept output
+func (b *tailBuffer) String() string {
+	return string(b.data)
+}
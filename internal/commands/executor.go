@@ -1,13 +1,15 @@
 package commands
 
 import (
-	"fmt"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"regexp"
 	"strings"
 
 	"github.com/simplyzetax/oracle/internal/ui"
+	"github.com/simplyzetax/oracle/pkg/types"
 )
 
 // ExtractCommands finds potential shell commands in AI response text
@@ -180,26 +182,45 @@ func isValidCommand(cmd string) bool {
 	return true
 }
 
-// PromptToExecute asks the user if they want to execute detected commands with simple prompts
-func PromptToExecute(commands []string) []string {
-	if len(commands) == 0 {
+// PromptToExecute asks the user which of the proposed commands to execute with simple prompts
+func PromptToExecute(proposals []types.CommandProposal) []types.CommandProposal {
+	if len(proposals) == 0 {
 		return nil
 	}
 
-	var toExecute []string
+	var toExecute []types.CommandProposal
 
-	for _, cmd := range commands {
-		ui.ShowCommandSuggestion(cmd)
-		if ui.ConfirmExecution(cmd) {
-			toExecute = append(toExecute, cmd)
+	for _, proposal := range proposals {
+		ui.ShowCommandProposal(proposal)
+		if ui.ConfirmExecution(proposal.Command) {
+			toExecute = append(toExecute, proposal)
 		}
 	}
 
 	return toExecute
 }
 
-// ExecuteCommand runs a shell command with minimal output
-func ExecuteCommand(command string) error {
+// ExecuteCommand runs a shell command in workingDir (or the current directory) with minimal output and returns its exit code
+func ExecuteCommand(command, workingDir string) (int, error) {
+	return runCommand(command, workingDir, ui.CommandOutput(), os.Stderr)
+}
+
+// CaptureCommand runs a command like ExecuteCommand while also keeping the end of its output for the model
+func CaptureCommand(command, workingDir string) (int, *CapturedOutput, error) {
+	stdout := &tailBuffer{limit: MaxCapturedOutput}
+	stderr := &tailBuffer{limit: MaxCapturedOutput}
+
+	exitCode, err := runCommand(command, workingDir, io.MultiWriter(ui.CommandOutput(), stdout), io.MultiWriter(os.Stderr, stderr))
+
+	return exitCode, &CapturedOutput{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.truncated || stderr.truncated,
+	}, err
+}
+
+// runCommand runs a command with the user's shell, writing its output to stdout and stderr
+func runCommand(command, workingDir string, stdout, stderr io.Writer) (int, error) {
 	// Use the user's default shell
 	shell := os.Getenv("SHELL")
 	if shell == "" {
@@ -207,30 +228,46 @@ func ExecuteCommand(command string) error {
 	}
 
 	cmd := exec.Command(shell, "-c", command)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Dir = workingDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	cmd.Stdin = os.Stdin
 
-	err := cmd.Run()
+	ui.ShowExecStarted(command)
+	exitCode, err := 0, cmd.Run()
 	if err != nil {
-		fmt.Printf("Command failed: %s\n", command)
-		return err
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
 	}
+	ui.ShowExecFinished(command, exitCode, err)
 
-	return nil
+	return exitCode, err
 }
 
-// ExecuteCommands runs multiple commands in sequence with minimal logging
-func ExecuteCommands(commands []string) {
-	if len(commands) == 0 {
-		return
+// ExecuteCommands runs multiple commands in sequence with minimal logging and records each one that ran
+func ExecuteCommands(proposals []types.CommandProposal) []types.CommandRecord {
+	if len(proposals) == 0 {
+		return nil
 	}
 
-	for _, cmd := range commands {
-		if err := ExecuteCommand(cmd); err != nil {
+	var records []types.CommandRecord
+
+	for _, proposal := range proposals {
+		exitCode, err := ExecuteCommand(proposal.Command, proposal.WorkingDir)
+		records = append(records, types.CommandRecord{
+			Command:  proposal.Command,
+			Executed: true,
+			ExitCode: exitCode,
+		})
+		if err != nil {
 			if !ui.ConfirmContinueOnError() {
-				return
+				return records
 			}
 		}
 	}
+
+	return records
 }
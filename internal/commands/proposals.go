@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// ProposeCommandsTool is the name of the tool the model calls to suggest commands
+const ProposeCommandsTool = "propose_commands"
+
+// proposeCommandsArgs mirrors the parameters schema of the propose_commands tool
+type proposeCommandsArgs struct {
+	Commands []struct {
+		Command     string `json:"command"`
+		Description string `json:"description"`
+		WorkingDir  string `json:"working_dir"`
+		Risk        string `json:"risk"`
+		NeedsSudo   bool   `json:"needs_sudo"`
+	} `json:"commands"`
+}
+
+// ProposeCommandsDeclaration declares the tool the model uses to return structured command proposals
+func ProposeCommandsDeclaration() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        ProposeCommandsTool,
+		Description: "Propose shell commands for the user to review and optionally run. Call this whenever the answer involves running commands.",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"commands": {
+					Type:        genai.TypeArray,
+					Description: "The commands to run, in order",
+					Items: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"command": {
+								Type:        genai.TypeString,
+								Description: "A single shell command, exactly as it should be run",
+							},
+							"description": {
+								Type:        genai.TypeString,
+								Description: "A short explanation of what the command does",
+							},
+							"working_dir": {
+								Type:        genai.TypeString,
+								Description: "Directory to run the command in, empty for the current directory",
+							},
+							"risk": {
+								Type:        genai.TypeString,
+								Description: "How much damage the command can do if it is wrong",
+								Enum:        []string{types.RiskLow, types.RiskMedium, types.RiskHigh},
+							},
+							"needs_sudo": {
+								Type:        genai.TypeBoolean,
+								Description: "Whether the command must run with root privileges",
+							},
+						},
+						Required: []string{"command", "description", "risk"},
+					},
+				},
+			},
+			Required: []string{"commands"},
+		},
+	}
+}
+
+// ParseProposals extracts the command proposals from propose_commands tool calls
+func ParseProposals(calls []*genai.FunctionCall) ([]types.CommandProposal, error) {
+	var proposals []types.CommandProposal
+	seen := make(map[string]bool)
+
+	for _, call := range calls {
+		if call.Name != ProposeCommandsTool {
+			continue
+		}
+
+		// Round trip through JSON to decode the loosely typed arguments
+		data, err := json.Marshal(call.Args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s arguments: %w", ProposeCommandsTool, err)
+		}
+		var args proposeCommandsArgs
+		if err := json.Unmarshal(data, &args); err != nil {
+			return nil, fmt.Errorf("failed to parse %s arguments: %w", ProposeCommandsTool, err)
+		}
+
+		for _, c := range args.Commands {
+			cmd := strings.TrimSpace(c.Command)
+			// The same safety checks apply no matter how the command was proposed
+			if cmd == "" || seen[cmd] || !isValidCommand(cmd) {
+				continue
+			}
+			seen[cmd] = true
+
+			proposals = append(proposals, types.CommandProposal{
+				Command:     cmd,
+				Description: strings.TrimSpace(c.Description),
+				WorkingDir:  strings.TrimSpace(c.WorkingDir),
+				Risk:        normalizeRisk(c.Risk),
+				NeedsSudo:   c.NeedsSudo || strings.HasPrefix(cmd, "sudo "),
+			})
+		}
+	}
+
+	return proposals, nil
+}
+
+// ProposalsFromText scrapes commands from response text for providers without tool support
+func ProposalsFromText(text string) []types.CommandProposal {
+	var proposals []types.CommandProposal
+	for _, cmd := range ExtractCommands(text) {
+		proposals = append(proposals, types.CommandProposal{
+			Command:   cmd,
+			NeedsSudo: strings.HasPrefix(cmd, "sudo "),
+		})
+	}
+	return proposals
+}
+
+// FormatProposals renders proposals as markdown, for responses that consist only of a tool call
+func FormatProposals(proposals []types.CommandProposal) string {
+	var text strings.Builder
+	for _, p := range proposals {
+		if p.Description != "" {
+			text.WriteString(p.Description + "\n")
+		}
+		fmt.Fprintf(&text, "```bash\n%s\n```\n\n", p.Command)
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// normalizeRisk maps the model's risk rating to a known level, treating unknown ratings as medium
+func normalizeRisk(risk string) string {
+	switch risk = strings.ToLower(strings.TrimSpace(risk)); risk {
+	case types.RiskLow, types.RiskMedium, types.RiskHigh:
+		return risk
+	default:
+		return types.RiskMedium
+	}
+}
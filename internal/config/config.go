@@ -5,10 +5,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/simplyzetax/oracle/internal/attach"
+	"github.com/simplyzetax/oracle/internal/environment"
+	"github.com/simplyzetax/oracle/internal/provider"
 	"github.com/simplyzetax/oracle/pkg/types"
 )
 
+// apiKeyEnvVars maps provider names to the environment variable holding their API key
+var apiKeyEnvVars = map[string]string{
+	"openai": "OPENAI_API_KEY",
+}
+
+// baseURLEnvVars maps provider names to the environment variable holding their base URL
+var baseURLEnvVars = map[string]string{
+	"openai": "OPENAI_BASE_URL",
+	"ollama": "OLLAMA_HOST",
+}
+
 // GetConfigDir returns the oracle config directory path
 func GetConfigDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -157,3 +176,355 @@ func SetAPIKey(apiKey string) error {
 
 	return nil
 }
+
+// GetProvider retrieves the provider name from parameter, environment, or config
+func GetProvider(flagProvider string) (string, error) {
+	// 1. Check flag parameter first
+	if flagProvider != "" {
+		return flagProvider, nil
+	}
+
+	// 2. Check environment variable
+	if envProvider := os.Getenv("ORACLE_PROVIDER"); envProvider != "" {
+		return envProvider, nil
+	}
+
+	// 3. Check config file
+	config, err := LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if config.Provider != "" {
+		return config.Provider, nil
+	}
+
+	return provider.DefaultProvider, nil
+}
+
+// GetProviderAPIKey retrieves the API key for a provider from parameter, environment, or config
+func GetProviderAPIKey(name, flagAPIKey string) (string, error) {
+	// Gemini keeps using the top-level key and GOOGLE_AI_API_KEY
+	if name == "gemini" {
+		return GetAPIKey(flagAPIKey)
+	}
+
+	if flagAPIKey != "" {
+		return flagAPIKey, nil
+	}
+
+	if envKey := os.Getenv(apiKeyEnvVars[name]); envKey != "" {
+		return envKey, nil
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return config.Providers[name].APIKey, nil
+}
+
+// GetBaseURL retrieves the base URL for a provider from parameter, environment, or config
+func GetBaseURL(name, flagBaseURL string) (string, error) {
+	return getSetting(flagBaseURL, baseURLEnvVars[name], func(c *types.Config) string {
+		return c.Providers[name].BaseURL
+	})
+}
+
+// GetBackend retrieves the Gemini backend (gemini or vertex) from parameter, environment, or config
+func GetBackend(flagBackend string) (string, error) {
+	if flagBackend == "" {
+		// Honor the variable the genai SDK itself uses to select Vertex AI
+		switch strings.ToLower(os.Getenv("GOOGLE_GENAI_USE_VERTEXAI")) {
+		case "1", "true":
+			return provider.BackendVertexAI, nil
+		}
+	}
+
+	return getSetting(flagBackend, "", func(c *types.Config) string {
+		return c.Providers["gemini"].Backend
+	})
+}
+
+// GetProject retrieves the Vertex AI project from parameter, environment, or config
+func GetProject(flagProject string) (string, error) {
+	return getSetting(flagProject, "GOOGLE_CLOUD_PROJECT", func(c *types.Config) string {
+		return c.Providers["gemini"].Project
+	})
+}
+
+// GetLocation retrieves the Vertex AI location from parameter, environment, or config
+func GetLocation(flagLocation string) (string, error) {
+	return getSetting(flagLocation, "GOOGLE_CLOUD_LOCATION", func(c *types.Config) string {
+		return c.Providers["gemini"].Location
+	})
+}
+
+// GetProxy retrieves the HTTPS proxy from parameter or config, HTTPS_PROXY is honored when unset
+func GetProxy(flagProxy string) (string, error) {
+	return getSetting(flagProxy, "", func(c *types.Config) string {
+		return c.Proxy
+	})
+}
+
+// GetCABundle retrieves the extra CA bundle path from parameter, environment, or config
+func GetCABundle(flagCABundle string) (string, error) {
+	return getSetting(flagCABundle, "ORACLE_CA_BUNDLE", func(c *types.Config) string {
+		return c.CABundle
+	})
+}
+
+// GetTokenBudget retrieves the attachment token budget from parameter or config
+func GetTokenBudget(flagBudget int) (int, error) {
+	if flagBudget > 0 {
+		return flagBudget, nil
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if config.TokenBudget > 0 {
+		return config.TokenBudget, nil
+	}
+
+	return attach.DefaultTokenBudget, nil
+}
+
+// GetModel retrieves the model from parameter or config, empty when neither sets one
+func GetModel(flagModel string) (string, error) {
+	return getSetting(flagModel, "", func(c *types.Config) string { return c.Model })
+}
+
+// GetGeneration returns the default generation parameters from the config
+func GetGeneration() (types.GenerationConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return types.GenerationConfig{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	return config.Generation, nil
+}
+
+// GetPrices returns the model prices from the config that override the built-in table
+func GetPrices() (map[string]types.Price, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return config.Prices, nil
+}
+
+// GetBudget returns the token budgets from the config, blocking by default when one is used up
+func GetBudget() (types.UsageBudget, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return types.UsageBudget{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	budget := config.Budget
+	switch budget.Action {
+	case "":
+		budget.Action = types.BudgetBlock
+	case types.BudgetBlock, types.BudgetWarn:
+	default:
+		return types.UsageBudget{}, fmt.Errorf("unknown budget action %q (use %s or %s)", budget.Action, types.BudgetBlock, types.BudgetWarn)
+	}
+	return budget, nil
+}
+
+// Response cache defaults, used when the config doesn't set them
+const (
+	DefaultCacheTTL       = 24 * time.Hour
+	DefaultCacheMaxSizeMB = 50
+)
+
+// CacheSettings are the response cache settings resolved from the config
+type CacheSettings struct {
+	Enabled bool
+	TTL     time.Duration
+	MaxSize int64
+}
+
+// GetCacheSettings returns the response cache settings from the config, filling in defaults
+func GetCacheSettings() (CacheSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return CacheSettings{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	settings := CacheSettings{
+		Enabled: !config.Cache.Disabled,
+		TTL:     DefaultCacheTTL,
+		MaxSize: DefaultCacheMaxSizeMB << 20,
+	}
+	if config.Cache.TTL != "" {
+		ttl, err := time.ParseDuration(config.Cache.TTL)
+		if err != nil || ttl <= 0 {
+			return CacheSettings{}, fmt.Errorf("invalid cache TTL %q, use a duration like 30m or 24h", config.Cache.TTL)
+		}
+		settings.TTL = ttl
+	}
+	if config.Cache.MaxSizeMB < 0 {
+		return CacheSettings{}, fmt.Errorf("invalid cache size %d MB, it must not be negative", config.Cache.MaxSizeMB)
+	}
+	if config.Cache.MaxSizeMB > 0 {
+		settings.MaxSize = int64(config.Cache.MaxSizeMB) << 20
+	}
+	return settings, nil
+}
+
+// Fallback is a model to try when the ones before it can't answer
+type Fallback struct {
+	Provider string
+	Model    string
+}
+
+// GetFallbacks returns the fallback models from the config in order, entries without a known
+// provider prefix belonging to the primary provider
+func GetFallbacks(primaryProvider string) ([]Fallback, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fallbacks := make([]Fallback, 0, len(config.Fallbacks))
+	for _, entry := range config.Fallbacks {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			return nil, fmt.Errorf("empty entry in Fallbacks, use a model name or provider/model")
+		}
+
+		// Model names can contain slashes too, so only a registered provider counts as a prefix
+		fallback := Fallback{Provider: primaryProvider, Model: entry}
+		if name, model, ok := strings.Cut(entry, "/"); ok && slices.Contains(provider.Names(), name) {
+			fallback = Fallback{Provider: name, Model: model}
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+	return fallbacks, nil
+}
+
+// GetPersona returns the persona selected by parameter, environment or config, or nil when none is selected
+func GetPersona(flagPersona string) (string, *types.Persona, error) {
+	name, err := getSetting(flagPersona, "ORACLE_PERSONA", func(c *types.Config) string { return c.Persona })
+	if err != nil || name == "" {
+		return "", nil, err
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	persona, ok := config.Personas[name]
+	if !ok {
+		names := make([]string, 0, len(config.Personas))
+		for n := range config.Personas {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return "", nil, fmt.Errorf("unknown persona %q, no personas are defined in the config", name)
+		}
+		return "", nil, fmt.Errorf("unknown persona %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return name, &persona, nil
+}
+
+// GetSystemPromptFile retrieves the base system prompt file from parameter, environment or config
+func GetSystemPromptFile(flagFile string) (string, error) {
+	return getSetting(flagFile, "ORACLE_SYSTEM_PROMPT_FILE", func(c *types.Config) string { return c.SystemPromptFile })
+}
+
+// GetTemplatesDir returns the prompt templates directory from environment or config, defaulting to ~/.oracle/templates
+func GetTemplatesDir() (string, error) {
+	dir, err := getSetting("", "ORACLE_TEMPLATES_DIR", func(c *types.Config) string { return c.TemplatesDir })
+	if err != nil {
+		return "", err
+	}
+	if dir != "" {
+		return dir, nil
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(configDir, "templates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create templates directory: %w", err)
+	}
+	return dir, nil
+}
+
+// GetCassetteDir returns the replay cassette directory from environment or config, defaulting to ~/.oracle/cassettes
+func GetCassetteDir() (string, error) {
+	dir, err := getSetting("", "ORACLE_CASSETTE_DIR", func(c *types.Config) string { return c.CassetteDir })
+	if err != nil || dir != "" {
+		return dir, err
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "cassettes"), nil
+}
+
+// GetRecord reports whether responses should be recorded as cassettes, from parameter or environment
+func GetRecord(flagRecord bool) bool {
+	if flagRecord {
+		return true
+	}
+	record, _ := strconv.ParseBool(os.Getenv("ORACLE_RECORD"))
+	return record
+}
+
+// GetContextProbes returns the environment probes to run, none if disabled by flag or config
+func GetContextProbes(disabled bool) ([]string, error) {
+	if disabled {
+		return nil, nil
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if config.DisableContext {
+		return nil, nil
+	}
+	if len(config.ContextProbes) == 0 {
+		return environment.Names(), nil
+	}
+
+	for _, name := range config.ContextProbes {
+		if !environment.Valid(name) {
+			return nil, fmt.Errorf("unknown context probe %q in config (available: %s)", name, strings.Join(environment.Names(), ", "))
+		}
+	}
+	return config.ContextProbes, nil
+}
+
+// getSetting returns the first non-empty value from the flag, the environment variable, or the config
+func getSetting(flagValue, envVar string, fromConfig func(*types.Config) string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	if envVar != "" {
+		if envValue := os.Getenv(envVar); envValue != "" {
+			return envValue, nil
+		}
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return fromConfig(config), nil
+}
@@ -0,0 +1,180 @@
+package environment
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// probeTimeout bounds how long a probe that runs an external command may take
+const probeTimeout = 2 * time.Second
+
+// probe gathers one line of information about the environment, or returns "" when it finds nothing
+type probe struct {
+	name  string
+	label string
+	run   func() string
+}
+
+// probes are all available probes, in the order they appear in the prompt
+var probes = []probe{
+	{"os", "OS", probeOS},
+	{"shell", "Shell", probeShell},
+	{"package_manager", "Package managers", probePackageManagers},
+	{"cwd", "Working directory", probeCwd},
+	{"git", "Git", probeGit},
+	{"project", "Project", probeProject},
+}
+
+// Names returns the names of all available probes
+func Names() []string {
+	names := make([]string, len(probes))
+	for i, p := range probes {
+		names[i] = p.name
+	}
+	return names
+}
+
+// Valid reports whether name is a known probe
+func Valid(name string) bool {
+	for _, p := range probes {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe runs the named probes and formats what they found as a list for the system prompt
+func Describe(names []string) string {
+	enabled := make(map[string]bool)
+	for _, name := range names {
+		enabled[name] = true
+	}
+
+	var lines []string
+	for _, p := range probes {
+		if !enabled[p.name] {
+			continue
+		}
+		if value := p.run(); value != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", p.label, value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// probeOS describes the operating system, including the distribution on Linux
+func probeOS() string {
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+
+	switch runtime.GOOS {
+	case "linux":
+		if name := osReleaseName(); name != "" {
+			return fmt.Sprintf("%s (%s)", name, platform)
+		}
+	case "darwin":
+		if version := output("sw_vers", "-productVersion"); version != "" {
+			return fmt.Sprintf("macOS %s (%s)", version, platform)
+		}
+	}
+	return platform
+}
+
+// osReleaseName reads the distribution name from /etc/os-release
+func osReleaseName() string {
+	file, err := os.Open("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
+// probeShell names the user's shell
+func probeShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	if runtime.GOOS == "windows" {
+		if os.Getenv("PSModulePath") != "" {
+			return "powershell"
+		}
+		return "cmd"
+	}
+	return ""
+}
+
+// packageManagers are checked in order, so the system's native manager comes first
+var packageManagers = []string{
+	"brew", "port", "apt", "dnf", "yum", "pacman", "zypper", "apk", "emerge", "xbps-install",
+	"nix", "snap", "flatpak", "winget", "choco", "scoop",
+}
+
+// probePackageManagers lists the package managers found on the PATH
+func probePackageManagers() string {
+	var found []string
+	for _, name := range packageManagers {
+		if _, err := exec.LookPath(name); err == nil {
+			found = append(found, name)
+		}
+	}
+	return strings.Join(found, ", ")
+}
+
+// probeCwd returns the current working directory
+func probeCwd() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return cwd
+}
+
+// probeGit summarizes the branch and uncommitted changes of the current repository
+func probeGit() string {
+	branch := output("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if branch == "" {
+		return ""
+	}
+
+	status := output("git", "status", "--porcelain")
+	changes := 0
+	if status != "" {
+		changes = len(strings.Split(status, "\n"))
+	}
+
+	switch changes {
+	case 0:
+		return fmt.Sprintf("branch %s, clean", branch)
+	case 1:
+		return fmt.Sprintf("branch %s, 1 changed file", branch)
+	default:
+		return fmt.Sprintf("branch %s, %d changed files", branch, changes)
+	}
+}
+
+// output runs a command and returns its trimmed output, or "" if it fails or takes too long
+func output(name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
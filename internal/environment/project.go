@@ -0,0 +1,140 @@
+package environment
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxListed is how many scripts or targets are listed per project file
+const maxListed = 15
+
+// makeTarget matches a Makefile rule, excluding variable assignments and special targets
+var makeTarget = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.-]*)\s*:([^=]|$)`)
+
+// projectMarkers are files that identify a project type without needing to be parsed
+var projectMarkers = []struct {
+	file string
+	kind string
+}{
+	{"Cargo.toml", "Rust crate"},
+	{"pyproject.toml", "Python project"},
+	{"requirements.txt", "Python project"},
+	{"Gemfile", "Ruby project"},
+	{"pom.xml", "Maven project"},
+	{"build.gradle", "Gradle project"},
+	{"Dockerfile", "Dockerfile"},
+	{"docker-compose.yml", "Docker Compose"},
+	{"compose.yaml", "Docker Compose"},
+}
+
+// probeProject describes the project in the current directory from its build files
+func probeProject() string {
+	var found []string
+
+	if module := goModule(); module != "" {
+		found = append(found, "Go module "+module)
+	}
+	if pkg := packageJSON(); pkg != "" {
+		found = append(found, pkg)
+	}
+	if targets := makeTargets(); len(targets) > 0 {
+		found = append(found, "Makefile targets: "+strings.Join(targets, ", "))
+	}
+
+	seen := make(map[string]bool)
+	for _, marker := range projectMarkers {
+		if seen[marker.kind] {
+			continue
+		}
+		if _, err := os.Stat(marker.file); err == nil {
+			found = append(found, marker.kind)
+			seen[marker.kind] = true
+		}
+	}
+
+	return strings.Join(found, "; ")
+}
+
+// goModule returns the module path declared in go.mod
+func goModule() string {
+	file, err := os.Open("go.mod")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.TrimSpace(module)
+		}
+	}
+	return "(unnamed)"
+}
+
+// packageJSON describes a Node.js package and its npm scripts
+func packageJSON() string {
+	data, err := os.ReadFile("package.json")
+	if err != nil {
+		return ""
+	}
+
+	var pkg struct {
+		Name    string            `json:"name"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return "Node.js package"
+	}
+
+	description := "Node.js package"
+	if pkg.Name != "" {
+		description += " " + pkg.Name
+	}
+
+	scripts := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		scripts = append(scripts, name)
+	}
+	sort.Strings(scripts)
+	if len(scripts) > 0 {
+		description += fmt.Sprintf(" (scripts: %s)", strings.Join(limit(scripts), ", "))
+	}
+	return description
+}
+
+// makeTargets returns the targets defined in the Makefile
+func makeTargets() []string {
+	file, err := os.Open("Makefile")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var targets []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := makeTarget.FindStringSubmatch(scanner.Text())
+		if match == nil || seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		targets = append(targets, match[1])
+	}
+	return limit(targets)
+}
+
+// limit shortens a list to maxListed entries
+func limit(items []string) []string {
+	if len(items) > maxListed {
+		return append(items[:maxListed:maxListed], "…")
+	}
+	return items
+}
@@ -0,0 +1,233 @@
+package history
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// GetHistoryDir returns the directory history entries are stored in
+func GetHistoryDir() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	historyDir := filepath.Join(configDir, "history")
+
+	// History can contain sensitive output, so keep it private
+	if err := os.MkdirAll(historyDir, 0700); err != nil {
+		return "", err
+	}
+
+	return historyDir, nil
+}
+
+// NewID returns a new sortable entry ID
+func NewID() string {
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+	// Millisecond precision keeps entries written in quick succession in order
+	now := time.Now()
+	return fmt.Sprintf("%s%03d-%s", now.Format("20060102150405"), now.Nanosecond()/int(time.Millisecond), hex.EncodeToString(suffix))
+}
+
+// Save writes an entry to the history store, assigning an ID if it has none
+func Save(entry *types.HistoryEntry) error {
+	historyDir, err := GetHistoryDir()
+	if err != nil {
+		return fmt.Errorf("failed to get history directory: %w", err)
+	}
+
+	if entry.ID == "" {
+		entry.ID = NewID()
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(historyDir, entry.ID+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+
+	return nil
+}
+
+// List returns all history entries, newest first
+func List() ([]*types.HistoryEntry, error) {
+	historyDir, err := GetHistoryDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history directory: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(historyDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	entries := make([]*types.HistoryEntry, 0, len(files))
+	for _, file := range files {
+		entry, err := load(file)
+		if err != nil {
+			// Skip unreadable entries instead of failing the whole listing
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+
+	return entries, nil
+}
+
+// Get returns the entry with the given ID or unique ID prefix
+func Get(id string) (*types.HistoryEntry, error) {
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*types.HistoryEntry
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+		if strings.HasPrefix(entry.ID, id) {
+			matches = append(matches, entry)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no history entry matches %q", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%q matches %d history entries, use a longer ID", id, len(matches))
+	}
+}
+
+// Search returns the entries containing every term of the query, newest first
+func Search(query string) ([]*types.HistoryEntry, error) {
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+
+	var results []*types.HistoryEntry
+	for _, entry := range entries {
+		text := searchableText(entry)
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			results = append(results, entry)
+		}
+	}
+
+	return results, nil
+}
+
+// Remove deletes the entry with the given ID or unique ID prefix
+func Remove(id string) error {
+	entry, err := Get(id)
+	if err != nil {
+		return err
+	}
+
+	historyDir, err := GetHistoryDir()
+	if err != nil {
+		return fmt.Errorf("failed to get history directory: %w", err)
+	}
+
+	if err := os.Remove(filepath.Join(historyDir, entry.ID+".json")); err != nil {
+		return fmt.Errorf("failed to remove history entry: %w", err)
+	}
+
+	return nil
+}
+
+// LatestSessionID returns the session of the most recent entry
+func LatestSessionID() (string, error) {
+	entries, err := List()
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no previous conversation to continue")
+	}
+
+	if entries[0].SessionID == "" {
+		return "", fmt.Errorf("history entry %s has no session ID", entries[0].ID)
+	}
+	return entries[0].SessionID, nil
+}
+
+// Session returns the entries of a session, oldest first
+func Session(sessionID string) ([]*types.HistoryEntry, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("no session ID given")
+	}
+
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var session []*types.HistoryEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].SessionID == sessionID {
+			session = append(session, entries[i])
+		}
+	}
+
+	if len(session) == 0 {
+		return nil, fmt.Errorf("no session found with ID %q", sessionID)
+	}
+
+	return session, nil
+}
+
+// load reads a single history entry from disk
+func load(file string) (*types.HistoryEntry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry types.HistoryEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// searchableText returns the lowercased text of an entry used for full-text search
+func searchableText(entry *types.HistoryEntry) string {
+	parts := []string{entry.Question.Text, entry.Response.Text, entry.Question.Model}
+	for _, cmd := range entry.Commands {
+		parts = append(parts, cmd.Command)
+	}
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
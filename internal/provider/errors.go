@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// HTTPError is returned when a provider API responds with a non-success status
+type HTTPError struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is how long the server asked to wait before retrying, zero when it didn't say
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// newHTTPError builds an HTTPError from a failed response, extracting the error message if possible
+func newHTTPError(resp *http.Response) *HTTPError {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	// Most APIs return either {"error": {"message": ...}} or {"error": "..."}
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	message := strings.TrimSpace(string(data))
+	if err := json.Unmarshal(data, &body); err == nil && len(body.Error) > 0 {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		var plain string
+		if err := json.Unmarshal(body.Error, &nested); err == nil && nested.Message != "" {
+			message = nested.Message
+		} else if err := json.Unmarshal(body.Error, &plain); err == nil && plain != "" {
+			message = plain
+		}
+	}
+
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// Classify marks a provider error with the kind of failure it is, so rejected keys, exhausted
+// quotas and unreachable servers can be told apart. Errors that already have a kind are kept
+func Classify(err error) error {
+	if err == nil || types.KindOf(err) != types.ErrorGeneral {
+		return err
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if kind, ok := statusKind(httpErr.StatusCode); ok {
+			return types.NewError(kind, err)
+		}
+		return err
+	}
+
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		if geminiKeyRejected(apiErr) {
+			return types.NewError(types.ErrorAuth, err)
+		}
+		if kind, ok := statusKind(apiErr.Code); ok {
+			return types.NewError(kind, err)
+		}
+		return err
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) {
+		return types.NewError(types.ErrorNetwork, err)
+	}
+	return err
+}
+
+// statusKind maps an HTTP status to the kind of failure it means
+func statusKind(code int) (types.ErrorKind, bool) {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return types.ErrorAuth, true
+	case code == http.StatusTooManyRequests:
+		return types.ErrorQuota, true
+	case code >= http.StatusInternalServerError:
+		return types.ErrorNetwork, true
+	}
+	return "", false
+}
+
+// geminiKeyRejected reports whether the Gemini API refused the API key, which it reports as a bad request
+func geminiKeyRejected(err genai.APIError) bool {
+	for _, detail := range err.Details {
+		if reason, _ := detail["reason"].(string); reason == "API_KEY_INVALID" {
+			return true
+		}
+	}
+	return false
+}
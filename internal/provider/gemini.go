@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/auth/credentials"
+	"cloud.google.com/go/auth/httptransport"
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+func init() {
+	Register("gemini", newGemini)
+}
+
+// cloudPlatformScope is the OAuth scope needed to call Vertex AI
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// geminiProvider answers questions using the Google Gemini API or Vertex AI
+type geminiProvider struct {
+	client *genai.Client
+	// endpoint is the backend, with the project and location for Vertex AI
+	endpoint string
+}
+
+// newGemini creates a Gemini provider from the given options
+func newGemini(ctx context.Context, opts Options) (Provider, error) {
+	cc := &genai.ClientConfig{
+		HTTPOptions: genai.HTTPOptions{BaseURL: opts.BaseURL},
+	}
+
+	switch opts.Backend {
+	case "", BackendGeminiAPI:
+		if opts.APIKey == "" {
+			return nil, types.NewError(types.ErrorAuth, fmt.Errorf("API key is required. Set GOOGLE_AI_API_KEY environment variable or use --api-key flag"))
+		}
+		cc.Backend = genai.BackendGeminiAPI
+		cc.APIKey = opts.APIKey
+		cc.HTTPClient = opts.HTTPClient
+	case BackendVertexAI:
+		if opts.Project == "" || opts.Location == "" {
+			return nil, fmt.Errorf("Vertex AI requires a project and location. Use --project and --location or set GOOGLE_CLOUD_PROJECT and GOOGLE_CLOUD_LOCATION")
+		}
+		cc.Backend = genai.BackendVertexAI
+		cc.Project = opts.Project
+		cc.Location = opts.Location
+
+		// The SDK only authenticates clients it creates itself, so wrap custom transports here
+		if opts.HTTPClient != nil {
+			client, creds, err := newVertexHTTPClient(ctx, opts.HTTPClient)
+			if err != nil {
+				return nil, err
+			}
+			cc.HTTPClient = client
+			cc.Credentials = creds
+		}
+	default:
+		return nil, fmt.Errorf("unknown Gemini backend %q (use %q or %q)", opts.Backend, BackendGeminiAPI, BackendVertexAI)
+	}
+
+	client, err := genai.NewClient(ctx, cc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AI client: %w", err)
+	}
+
+	endpoint := BackendGeminiAPI
+	if cc.Backend == genai.BackendVertexAI {
+		endpoint = BackendVertexAI + ":" + opts.Project + "/" + opts.Location
+	}
+	if opts.BaseURL != "" {
+		endpoint += "@" + opts.BaseURL
+	}
+
+	return &geminiProvider{client: client, endpoint: endpoint}, nil
+}
+
+// newVertexHTTPClient creates an HTTP client that adds Application Default Credentials on top of base
+func newVertexHTTPClient(ctx context.Context, base *http.Client) (*http.Client, *auth.Credentials, error) {
+	creds, err := credentials.DetectDefault(&credentials.DetectOptions{
+		Scopes: []string{cloudPlatformScope},
+		Client: base,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find default credentials: %w", err)
+	}
+
+	headers := http.Header{}
+	if quotaProject, err := creds.QuotaProjectID(ctx); err == nil && quotaProject != "" {
+		headers.Set("X-Goog-User-Project", quotaProject)
+	}
+
+	client, err := httptransport.NewClient(&httptransport.Options{
+		Credentials:      creds,
+		Headers:          headers,
+		BaseRoundTripper: base.Transport,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	return client, creds, nil
+}
+
+// Name returns the provider name
+func (g *geminiProvider) Name() string {
+	return "gemini"
+}
+
+// Endpoint returns the backend, Vertex AI's project and location and any custom base URL
+func (g *geminiProvider) Endpoint() string {
+	return g.endpoint
+}
+
+// Stream generates content and yields each streamed response as a chunk
+func (g *geminiProvider) Stream(ctx context.Context, req *Request) iter.Seq2[*Chunk, error] {
+	return func(yield func(*Chunk, error) bool) {
+		config := generateContentConfig(req.Generation)
+		if req.SystemPrompt != "" {
+			config.SystemInstruction = genai.NewContentFromText(req.SystemPrompt, genai.RoleUser)
+		}
+		if len(req.Tools) > 0 {
+			config.Tools = []*genai.Tool{{FunctionDeclarations: req.Tools}}
+		}
+
+		for result, err := range g.client.Models.GenerateContentStream(ctx, req.Model, req.Contents, config) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(toChunk(result), nil) {
+				return
+			}
+			if err := blockedError(result); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// blockedError returns a safety error when Gemini blocked the prompt or stopped the response because of its content
+func blockedError(result *genai.GenerateContentResponse) error {
+	if feedback := result.PromptFeedback; feedback != nil && feedback.BlockReason != "" {
+		reason := feedback.BlockReasonMessage
+		if reason == "" {
+			reason = string(feedback.BlockReason)
+		}
+		return types.NewError(types.ErrorSafety, fmt.Errorf("gemini blocked the prompt (%s)", reason))
+	}
+
+	for _, c := range result.Candidates {
+		if c.Index != 0 {
+			continue
+		}
+		switch c.FinishReason {
+		case genai.FinishReasonSafety, genai.FinishReasonBlocklist, genai.FinishReasonProhibitedContent,
+			genai.FinishReasonSPII, genai.FinishReasonImageSafety:
+			return types.NewError(types.ErrorSafety, fmt.Errorf("gemini stopped the response (%s)", c.FinishReason))
+		}
+	}
+	return nil
+}
+
+// generateContentConfig maps sampling parameters onto the Gemini request config
+func generateContentConfig(g types.GenerationConfig) *genai.GenerateContentConfig {
+	config := &genai.GenerateContentConfig{
+		Temperature:   g.Temperature,
+		TopP:          g.TopP,
+		StopSequences: g.StopSequences,
+	}
+	if g.TopK != nil {
+		config.TopK = genai.Ptr(float32(*g.TopK))
+	}
+	if g.MaxOutputTokens != nil {
+		config.MaxOutputTokens = int32(*g.MaxOutputTokens)
+	}
+	if g.Seed != nil {
+		config.Seed = genai.Ptr(int32(*g.Seed))
+	}
+	if g.CandidateCount != nil {
+		config.CandidateCount = int32(*g.CandidateCount)
+	}
+	return config
+}
+
+// toChunk collects the text and function calls of the first candidate, reading parts
+// directly since result.Text() logs a warning whenever a function call is present
+func toChunk(result *genai.GenerateContentResponse) *Chunk {
+	chunk := &Chunk{}
+	if meta := result.UsageMetadata; meta != nil {
+		chunk.Usage = &types.Usage{
+			PromptTokens:   int(meta.PromptTokenCount),
+			ResponseTokens: int(meta.CandidatesTokenCount),
+			ThinkingTokens: int(meta.ThoughtsTokenCount),
+		}
+	}
+
+	// With several candidates the stream interleaves them, only the first is shown
+	var candidate *genai.Candidate
+	for _, c := range result.Candidates {
+		if c.Index == 0 {
+			candidate = c
+			break
+		}
+	}
+	if candidate == nil || candidate.Content == nil {
+		return chunk
+	}
+
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+			chunk.FunctionCalls = append(chunk.FunctionCalls, part.FunctionCall)
+		case !part.Thought:
+			text.WriteString(part.Text)
+		}
+	}
+	chunk.Text = text.String()
+	return chunk
+}
+
+// ListModels returns the models that support content generation
+func (g *geminiProvider) ListModels(ctx context.Context) ([]Model, error) {
+	var models []Model
+	for m, err := range g.client.Models.All(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list models: %w", err)
+		}
+		if !supportsAction(m.SupportedActions, "generateContent") {
+			continue
+		}
+		name := strings.TrimPrefix(m.Name, "models/")
+
+		// The API doesn't report capabilities, but every Gemini model takes images and calls tools
+		models = append(models, Model{
+			Name:             name,
+			DisplayName:      m.DisplayName,
+			InputTokenLimit:  int(m.InputTokenLimit),
+			OutputTokenLimit: int(m.OutputTokenLimit),
+			Vision:           strings.HasPrefix(name, "gemini-") || strings.HasPrefix(name, "gemma-3"),
+			Tools:            strings.HasPrefix(name, "gemini-"),
+			Thinking:         strings.HasPrefix(name, "gemini-2.5") || strings.Contains(name, "thinking"),
+		})
+	}
+	return models, nil
+}
+
+// CountTokens counts tokens using the Gemini token counting endpoint
+func (g *geminiProvider) CountTokens(ctx context.Context, model string, contents []*genai.Content) (int, error) {
+	resp, err := g.client.Models.CountTokens(ctx, model, contents, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return int(resp.TotalTokens), nil
+}
+
+// DefaultModel returns the Gemini model used when none is configured
+func (g *geminiProvider) DefaultModel() string {
+	return "gemini-2.0-flash-exp"
+}
+
+// SupportsTools reports that Gemini supports function calling
+func (g *geminiProvider) SupportsTools() bool {
+	return true
+}
+
+// ValidateGeneration checks sampling parameters against the Gemini API limits
+func (g *geminiProvider) ValidateGeneration(model string, gen types.GenerationConfig) error {
+	return validateGeneration("gemini", gen, generationLimits{
+		maxTemperature:   2,
+		topK:             true,
+		maxStopSequences: 5,
+		maxCandidates:    8,
+	})
+}
+
+// supportsAction reports whether action is in the list of supported actions
+func supportsAction(actions []string, action string) bool {
+	// Vertex AI does not report supported actions, so assume support
+	if len(actions) == 0 {
+		return true
+	}
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// defaultOllamaBaseURL is the address a local Ollama server listens on by default
+const defaultOllamaBaseURL = "http://localhost:11434"
+
+func init() {
+	Register("ollama", newOllama)
+}
+
+// ollamaProvider answers questions using a local Ollama server
+type ollamaProvider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// ollamaMessage is a single chat message in the Ollama wire format
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Images holds base64-encoded images for multimodal models
+	Images []string `json:"images,omitempty"`
+}
+
+// ollamaChatRequest is the body of an /api/chat request
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+// ollamaChatResponse is a single NDJSON line of a streamed chat response
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+
+	// Token counts, only set on the final line
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+// newOllama creates an Ollama provider from the given options, no API key is needed
+func newOllama(ctx context.Context, opts Options) (Provider, error) {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	// OLLAMA_HOST is commonly set without a scheme
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &ollamaProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}, nil
+}
+
+// Name returns the provider name
+func (o *ollamaProvider) Name() string {
+	return "ollama"
+}
+
+// Endpoint returns the URL of the Ollama server
+func (o *ollamaProvider) Endpoint() string {
+	return o.baseURL
+}
+
+// Stream sends a streaming chat request and yields each message fragment
+func (o *ollamaProvider) Stream(ctx context.Context, req *Request) iter.Seq2[*Chunk, error] {
+	return func(yield func(*Chunk, error) bool) {
+		chatReq := ollamaChatRequest{
+			Model:    req.Model,
+			Messages: toOllamaMessages(req.SystemPrompt, req.Contents),
+			Stream:   true,
+		}
+		chatReq.Options = ollamaOptions(req.Generation)
+
+		body, err := json.Marshal(chatReq)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to marshal request: %w", err))
+			return
+		}
+
+		resp, err := o.do(ctx, http.MethodPost, "/api/chat", body)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			var chunk ollamaChatResponse
+			if err := json.Unmarshal(line, &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to parse stream chunk: %w", err))
+				return
+			}
+			if chunk.Error != "" {
+				yield(nil, fmt.Errorf("ollama error: %s", chunk.Error))
+				return
+			}
+
+			if chunk.Message.Content != "" {
+				if !yield(&Chunk{Text: chunk.Message.Content}, nil) {
+					return
+				}
+			}
+			if chunk.Done {
+				yield(&Chunk{Usage: &types.Usage{PromptTokens: chunk.PromptEvalCount, ResponseTokens: chunk.EvalCount}}, nil)
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+		}
+	}
+}
+
+// ollamaOptions maps sampling parameters onto Ollama model options
+func ollamaOptions(g types.GenerationConfig) map[string]any {
+	options := map[string]any{}
+	if g.Temperature != nil {
+		options["temperature"] = *g.Temperature
+	}
+	if g.TopP != nil {
+		options["top_p"] = *g.TopP
+	}
+	if g.TopK != nil {
+		options["top_k"] = *g.TopK
+	}
+	if g.MaxOutputTokens != nil {
+		options["num_predict"] = *g.MaxOutputTokens
+	}
+	if len(g.StopSequences) > 0 {
+		options["stop"] = g.StopSequences
+	}
+	if g.Seed != nil {
+		options["seed"] = *g.Seed
+	}
+	return options
+}
+
+// ListModels returns the models pulled into the local Ollama server
+func (o *ollamaProvider) ListModels(ctx context.Context) ([]Model, error) {
+	resp, err := o.do(ctx, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %w", err)
+	}
+
+	models := make([]Model, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		model := Model{Name: m.Name, DisplayName: m.Name}
+		// Details are best effort, older servers don't report capabilities
+		if err := o.describeModel(ctx, &model); err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+// describeModel fills in a model's context window and capabilities from /api/show
+func (o *ollamaProvider) describeModel(ctx context.Context, model *Model) error {
+	body, err := json.Marshal(map[string]string{"model": model.Name})
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.do(ctx, http.MethodPost, "/api/show", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var show struct {
+		Capabilities []string       `json:"capabilities"`
+		ModelInfo    map[string]any `json:"model_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return fmt.Errorf("failed to parse model details: %w", err)
+	}
+
+	// The context length key is prefixed with the architecture, e.g. llama.context_length
+	for key, value := range show.ModelInfo {
+		if length, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			model.InputTokenLimit = int(length)
+		}
+	}
+
+	// Tools aren't sent to Ollama yet, so they aren't reported even when the model has them
+	for _, capability := range show.Capabilities {
+		switch capability {
+		case "vision":
+			model.Vision = true
+		case "thinking":
+			model.Thinking = true
+		}
+	}
+	return nil
+}
+
+// CountTokens estimates the token count since Ollama has no counting endpoint
+func (o *ollamaProvider) CountTokens(ctx context.Context, model string, contents []*genai.Content) (int, error) {
+	return estimateTokens(contents), nil
+}
+
+// ValidateGeneration checks sampling parameters against what Ollama supports
+func (o *ollamaProvider) ValidateGeneration(model string, g types.GenerationConfig) error {
+	return validateGeneration("ollama", g, generationLimits{
+		maxTemperature:   2,
+		topK:             true,
+		maxStopSequences: 16,
+		maxCandidates:    1,
+	})
+}
+
+// DefaultModel returns no model since which models are available depends on what was pulled
+func (o *ollamaProvider) DefaultModel() string {
+	return ""
+}
+
+// SupportsTools reports false since tool support depends on the pulled model and
+// models without it reject the whole request
+func (o *ollamaProvider) SupportsTools() bool {
+	return false
+}
+
+// do sends a request to the Ollama server and returns the response if it succeeded
+func (o *ollamaProvider) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, o.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Ollama at %s (is `ollama serve` running?): %w", o.baseURL, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newHTTPError(resp)
+	}
+
+	return resp, nil
+}
+
+// toOllamaMessages converts a system prompt and genai contents into chat messages
+func toOllamaMessages(systemPrompt string, contents []*genai.Content) []ollamaMessage {
+	var messages []ollamaMessage
+	if systemPrompt != "" {
+		messages = append(messages, ollamaMessage{Role: "system", Content: systemPrompt})
+	}
+
+	for _, content := range contents {
+		role := "user"
+		if content.Role == genai.RoleModel {
+			role = "assistant"
+		}
+
+		message := ollamaMessage{Role: role, Content: ContentText(content)}
+		for _, part := range content.Parts {
+			if part.InlineData != nil {
+				message.Images = append(message.Images, base64.StdEncoding.EncodeToString(part.InlineData.Data))
+			}
+		}
+		messages = append(messages, message)
+	}
+
+	return messages
+}
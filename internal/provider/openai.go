@@ -0,0 +1,550 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// defaultOpenAIBaseURL is used when no base URL is configured
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+func init() {
+	Register("openai", newOpenAI)
+}
+
+// openAIProvider answers questions using an OpenAI-compatible chat completions API
+type openAIProvider struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// openAIMessage is a single chat message in the OpenAI wire format
+type openAIMessage struct {
+	Role string `json:"role"`
+	// Content is a string, or a list of openAIContentPart when the message has images
+	Content any `json:"content"`
+	// ToolCalls are the calls an assistant message makes, ToolCallID links a tool message to one
+	ToolCalls  []openAIMessageToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string                  `json:"tool_call_id,omitempty"`
+}
+
+// openAIMessageToolCall is a tool call as sent back in the conversation history
+type openAIMessageToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAIContentPart is a text or image part of a multimodal chat message
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+// openAIImageURL references an image, here always as a base64 data URL
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+// openAIChatRequest is the body of a chat completions request
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Stream      bool            `json:"stream"`
+	Temperature *float32        `json:"temperature,omitempty"`
+	TopP        *float32        `json:"top_p,omitempty"`
+	MaxTokens   *int            `json:"max_tokens,omitempty"`
+	// MaxCompletionTokens replaces MaxTokens for reasoning models, which reject it
+	MaxCompletionTokens *int         `json:"max_completion_tokens,omitempty"`
+	Stop                []string     `json:"stop,omitempty"`
+	Seed                *int         `json:"seed,omitempty"`
+	N                   *int         `json:"n,omitempty"`
+	Tools               []openAITool `json:"tools,omitempty"`
+
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+// openAIStreamOptions asks for a final chunk reporting the token usage
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIUsage is the token usage reported at the end of a stream
+type openAIUsage struct {
+	PromptTokens            int `json:"prompt_tokens"`
+	CompletionTokens        int `json:"completion_tokens"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+// openAITool declares a function the model may call
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+// openAIFunction describes a callable function and its JSON schema parameters
+type openAIFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+// openAIStreamChunk is a single server-sent event payload of a streamed completion
+type openAIStreamChunk struct {
+	Choices []struct {
+		Index        int    `json:"index"`
+		FinishReason string `json:"finish_reason"`
+		Delta        struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+// openAIToolCall accumulates a tool call whose arguments arrive in fragments
+type openAIToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// newOpenAI creates an OpenAI-compatible provider from the given options
+func newOpenAI(ctx context.Context, opts Options) (Provider, error) {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	// The API key is optional since local servers usually don't need one
+	return &openAIProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     opts.APIKey,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Name returns the provider name
+func (o *openAIProvider) Name() string {
+	return "openai"
+}
+
+// Endpoint returns the base URL of the API
+func (o *openAIProvider) Endpoint() string {
+	return o.baseURL
+}
+
+// Stream sends a streaming chat completion request and yields each content delta
+func (o *openAIProvider) Stream(ctx context.Context, req *Request) iter.Seq2[*Chunk, error] {
+	return func(yield func(*Chunk, error) bool) {
+		chatReq := openAIChatRequest{
+			Model:       req.Model,
+			Messages:    toOpenAIMessages(req.SystemPrompt, req.Contents),
+			Stream:      true,
+			Temperature: req.Generation.Temperature,
+			TopP:        req.Generation.TopP,
+			MaxTokens:   req.Generation.MaxOutputTokens,
+			Stop:        req.Generation.StopSequences,
+			Seed:        req.Generation.Seed,
+			N:           req.Generation.CandidateCount,
+			Tools:       toOpenAITools(req.Tools),
+
+			StreamOptions: &openAIStreamOptions{IncludeUsage: true},
+		}
+		if isReasoningModel(req.Model) {
+			chatReq.MaxTokens, chatReq.MaxCompletionTokens = nil, chatReq.MaxTokens
+		}
+		body, err := json.Marshal(chatReq)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to marshal request: %w", err))
+			return
+		}
+
+		resp, err := o.do(ctx, http.MethodPost, "/chat/completions", body)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		// Tool calls are streamed in fragments, so they are only yielded once the stream ends
+		var toolCalls []*openAIToolCall
+		finish := func() {
+			if len(toolCalls) == 0 {
+				return
+			}
+			calls, err := toFunctionCalls(toolCalls)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			yield(&Chunk{FunctionCalls: calls}, nil)
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				finish()
+				return
+			}
+
+			var chunk openAIStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to parse stream chunk: %w", err))
+				return
+			}
+
+			// Completion tokens include reasoning tokens, which are counted separately here
+			if u := chunk.Usage; u != nil {
+				usage := &types.Usage{
+					PromptTokens:   u.PromptTokens,
+					ResponseTokens: u.CompletionTokens - u.CompletionTokensDetails.ReasoningTokens,
+					ThinkingTokens: u.CompletionTokensDetails.ReasoningTokens,
+				}
+				if !yield(&Chunk{Usage: usage}, nil) {
+					return
+				}
+			}
+
+			for _, choice := range chunk.Choices {
+				// With several candidates the stream interleaves them, only the first is shown
+				if choice.Index != 0 {
+					continue
+				}
+				for _, delta := range choice.Delta.ToolCalls {
+					for len(toolCalls) <= delta.Index {
+						toolCalls = append(toolCalls, &openAIToolCall{})
+					}
+					call := toolCalls[delta.Index]
+					if delta.ID != "" {
+						call.id = delta.ID
+					}
+					call.name += delta.Function.Name
+					call.arguments.WriteString(delta.Function.Arguments)
+				}
+
+				if choice.Delta.Content != "" && !yield(&Chunk{Text: choice.Delta.Content}, nil) {
+					return
+				}
+				if choice.FinishReason == "content_filter" {
+					yield(nil, types.NewError(types.ErrorSafety, fmt.Errorf("the response was stopped by the content filter")))
+					return
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+			return
+		}
+		finish()
+	}
+}
+
+// ListModels returns the models served by the endpoint
+func (o *openAIProvider) ListModels(ctx context.Context) ([]Model, error) {
+	resp, err := o.do(ctx, http.MethodGet, "/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %w", err)
+	}
+
+	models := make([]Model, 0, len(list.Data))
+	for _, m := range list.Data {
+		if !isChatModel(m.ID) {
+			continue
+		}
+		models = append(models, openAIModel(m.ID))
+	}
+	return models, nil
+}
+
+// nonChatModelPrefixes are OpenAI models that can't be used for chat completions
+var nonChatModelPrefixes = []string{"text-embedding", "whisper", "tts-", "dall-e", "omni-moderation", "text-moderation", "davinci", "babbage"}
+
+// isChatModel reports whether a model from the list can answer chat completions
+func isChatModel(id string) bool {
+	for _, prefix := range nonChatModelPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// openAIModelFamilies describes known OpenAI model families, since /models only returns IDs.
+// Entries match models whose ID starts with the prefix, the longest prefix wins
+var openAIModelFamilies = map[string]Model{
+	"gpt-3.5-turbo": {InputTokenLimit: 16385, OutputTokenLimit: 4096, Tools: true},
+	"gpt-4":         {InputTokenLimit: 8192, OutputTokenLimit: 8192, Tools: true},
+	"gpt-4-turbo":   {InputTokenLimit: 128000, OutputTokenLimit: 4096, Vision: true, Tools: true},
+	"gpt-4o":        {InputTokenLimit: 128000, OutputTokenLimit: 16384, Vision: true, Tools: true},
+	"gpt-4.1":       {InputTokenLimit: 1047576, OutputTokenLimit: 32768, Vision: true, Tools: true},
+	"gpt-5":         {InputTokenLimit: 400000, OutputTokenLimit: 128000, Vision: true, Tools: true, Thinking: true},
+	"o1":            {InputTokenLimit: 200000, OutputTokenLimit: 100000, Vision: true, Tools: true, Thinking: true},
+	"o1-mini":       {InputTokenLimit: 128000, OutputTokenLimit: 65536, Thinking: true},
+	"o3":            {InputTokenLimit: 200000, OutputTokenLimit: 100000, Vision: true, Tools: true, Thinking: true},
+	"o3-mini":       {InputTokenLimit: 200000, OutputTokenLimit: 100000, Tools: true, Thinking: true},
+	"o4-mini":       {InputTokenLimit: 200000, OutputTokenLimit: 100000, Vision: true, Tools: true, Thinking: true},
+}
+
+// openAIModel describes a model by its ID, using what is known about its family
+func openAIModel(id string) Model {
+	var family string
+	for prefix := range openAIModelFamilies {
+		if strings.HasPrefix(id, prefix) && len(prefix) > len(family) {
+			family = prefix
+		}
+	}
+
+	model := openAIModelFamilies[family]
+	model.Name = id
+	model.DisplayName = id
+	return model
+}
+
+// isReasoningModel reports whether a model is an OpenAI reasoning model, which only samples at
+// temperature 1 and limits its output with max_completion_tokens
+func isReasoningModel(id string) bool {
+	return openAIModel(id).Thinking
+}
+
+// CountTokens estimates the token count since the API has no counting endpoint
+func (o *openAIProvider) CountTokens(ctx context.Context, model string, contents []*genai.Content) (int, error) {
+	return estimateTokens(contents), nil
+}
+
+// DefaultModel returns the OpenAI model used when none is configured
+func (o *openAIProvider) DefaultModel() string {
+	return "gpt-4o-mini"
+}
+
+// SupportsTools reports that OpenAI-compatible APIs support function calling
+func (o *openAIProvider) SupportsTools() bool {
+	return true
+}
+
+// ValidateGeneration checks sampling parameters against the chat completions API limits
+func (o *openAIProvider) ValidateGeneration(model string, g types.GenerationConfig) error {
+	if isReasoningModel(model) && g.Temperature != nil && *g.Temperature != 1 {
+		return fmt.Errorf("%s is a reasoning model and only accepts a temperature of 1, got %g", model, *g.Temperature)
+	}
+	return validateGeneration("openai", g, generationLimits{
+		maxTemperature:   2,
+		maxStopSequences: 4,
+		maxCandidates:    128,
+	})
+}
+
+// do sends a request to the API and returns the response if it succeeded
+func (o *openAIProvider) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, o.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newHTTPError(resp)
+	}
+
+	return resp, nil
+}
+
+// toOpenAIMessages converts a system prompt and genai contents into chat messages
+func toOpenAIMessages(systemPrompt string, contents []*genai.Content) []openAIMessage {
+	var messages []openAIMessage
+	if systemPrompt != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: systemPrompt})
+	}
+
+	for _, content := range contents {
+		role := "user"
+		if content.Role == genai.RoleModel {
+			role = "assistant"
+		}
+
+		// Function results become tool messages, one per call they answer
+		if responses := functionResponses(content); len(responses) > 0 {
+			for _, r := range responses {
+				result, _ := json.Marshal(r.Response)
+				messages = append(messages, openAIMessage{Role: "tool", Content: string(result), ToolCallID: r.ID})
+			}
+			continue
+		}
+
+		message := openAIMessage{Role: role, Content: toOpenAIContent(content)}
+		for _, part := range content.Parts {
+			if part.FunctionCall == nil {
+				continue
+			}
+			args, _ := json.Marshal(part.FunctionCall.Args)
+			call := openAIMessageToolCall{ID: part.FunctionCall.ID, Type: "function"}
+			call.Function.Name = part.FunctionCall.Name
+			call.Function.Arguments = string(args)
+			message.ToolCalls = append(message.ToolCalls, call)
+		}
+		// Assistant messages that only call tools have no content
+		if len(message.ToolCalls) > 0 && message.Content == "" {
+			message.Content = nil
+		}
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+// functionResponses returns the function response parts of a content
+func functionResponses(content *genai.Content) []*genai.FunctionResponse {
+	var responses []*genai.FunctionResponse
+	for _, part := range content.Parts {
+		if part.FunctionResponse != nil {
+			responses = append(responses, part.FunctionResponse)
+		}
+	}
+	return responses
+}
+
+// toOpenAIContent returns the text of a content, or a list of parts when it includes images
+func toOpenAIContent(content *genai.Content) any {
+	if !hasInlineData(content) {
+		return ContentText(content)
+	}
+
+	var parts []openAIContentPart
+	for _, part := range content.Parts {
+		switch {
+		case part.InlineData != nil:
+			url := "data:" + part.InlineData.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(part.InlineData.Data)
+			parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: url}})
+		case part.Text != "":
+			parts = append(parts, openAIContentPart{Type: "text", Text: part.Text})
+		}
+	}
+	return parts
+}
+
+// toOpenAITools converts function declarations into OpenAI tool definitions
+func toOpenAITools(declarations []*genai.FunctionDeclaration) []openAITool {
+	var tools []openAITool
+	for _, d := range declarations {
+		tools = append(tools, openAITool{
+			Type: "function",
+			Function: openAIFunction{
+				Name:        d.Name,
+				Description: d.Description,
+				Parameters:  toJSONSchema(d.Parameters),
+			},
+		})
+	}
+	return tools
+}
+
+// toJSONSchema converts a genai schema, which uses upper case type names, into standard JSON schema
+func toJSONSchema(schema *genai.Schema) map[string]any {
+	if schema == nil {
+		return nil
+	}
+
+	out := map[string]any{}
+	if schema.Type != "" {
+		out["type"] = strings.ToLower(string(schema.Type))
+	}
+	if schema.Description != "" {
+		out["description"] = schema.Description
+	}
+	if len(schema.Enum) > 0 {
+		out["enum"] = schema.Enum
+	}
+	if len(schema.Required) > 0 {
+		out["required"] = schema.Required
+	}
+	if schema.Items != nil {
+		out["items"] = toJSONSchema(schema.Items)
+	}
+	if len(schema.Properties) > 0 {
+		properties := map[string]any{}
+		for name, property := range schema.Properties {
+			properties[name] = toJSONSchema(property)
+		}
+		out["properties"] = properties
+	}
+	return out
+}
+
+// toFunctionCalls decodes the accumulated tool calls into genai function calls
+func toFunctionCalls(toolCalls []*openAIToolCall) ([]*genai.FunctionCall, error) {
+	calls := make([]*genai.FunctionCall, 0, len(toolCalls))
+	for _, tc := range toolCalls {
+		args := map[string]any{}
+		if raw := strings.TrimSpace(tc.arguments.String()); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &args); err != nil {
+				return nil, fmt.Errorf("failed to parse arguments of tool call %s: %w", tc.name, err)
+			}
+		}
+		calls = append(calls, &genai.FunctionCall{ID: tc.id, Name: tc.name, Args: args})
+	}
+	return calls, nil
+}
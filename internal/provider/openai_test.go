@@ -0,0 +1,216 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// newTestOpenAI creates an OpenAI-compatible provider talking to a stand-in server
+func newTestOpenAI(t *testing.T, handler http.HandlerFunc) Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	p, err := newOpenAI(context.Background(), Options{BaseURL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("newOpenAI: %v", err)
+	}
+	return p
+}
+
+// sseHandler answers every request with the given server-sent event payloads
+func sseHandler(events ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+// collect streams a request and returns its chunks and the error that ended it
+func collect(p Provider, req *Request) ([]*Chunk, error) {
+	var chunks []*Chunk
+	for chunk, err := range p.Stream(context.Background(), req) {
+		if err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// testRequest is a plain question without tools or generation parameters
+func testRequest() *Request {
+	return &Request{
+		Model:        "gpt-4o-mini",
+		SystemPrompt: "Be brief.",
+		Contents:     []*genai.Content{genai.NewContentFromText("Hello?", genai.RoleUser)},
+	}
+}
+
+func TestOpenAIStreamText(t *testing.T) {
+	var body openAIChatRequest
+	var auth, path string
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		sseHandler(
+			`{"choices":[{"index":0,"delta":{"content":"Hel"}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"lo"}},{"index":1,"delta":{"content":"ignored"}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":9,"completion_tokens_details":{"reasoning_tokens":4}}}`,
+			`[DONE]`,
+			`{"choices":[{"index":0,"delta":{"content":" after done"}}]}`,
+		)(w, r)
+	})
+
+	chunks, err := collect(p, testRequest())
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	var text strings.Builder
+	var usage *types.Usage
+	for _, chunk := range chunks {
+		text.WriteString(chunk.Text)
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	if text.String() != "Hello" {
+		t.Errorf("text = %q, want %q", text.String(), "Hello")
+	}
+	want := types.Usage{PromptTokens: 12, ResponseTokens: 5, ThinkingTokens: 4}
+	if usage == nil || *usage != want {
+		t.Errorf("usage = %+v, want %+v", usage, want)
+	}
+
+	if path != "/chat/completions" {
+		t.Errorf("path = %q, want /chat/completions", path)
+	}
+	if auth != "Bearer test-key" {
+		t.Errorf("Authorization = %q, want the bearer key", auth)
+	}
+	if !body.Stream || body.StreamOptions == nil || !body.StreamOptions.IncludeUsage {
+		t.Errorf("request doesn't ask for a stream with usage: %+v", body)
+	}
+	if len(body.Messages) != 2 || body.Messages[0].Role != "system" || body.Messages[1].Content != "Hello?" {
+		t.Errorf("messages = %+v, want the system prompt and the question", body.Messages)
+	}
+}
+
+func TestOpenAIStreamToolCallFragments(t *testing.T) {
+	p := newTestOpenAI(t, sseHandler(
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"propose_commands","arguments":"{\"comm"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ands\":[{\"command\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","function":{"name":"task_done","arguments":"{}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"ls\"}]}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`[DONE]`,
+	))
+
+	chunks, err := collect(p, testRequest())
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want the tool calls in one chunk", len(chunks))
+	}
+
+	want := []*genai.FunctionCall{
+		{ID: "call_1", Name: "propose_commands", Args: map[string]any{"commands": []any{map[string]any{"command": "ls"}}}},
+		{ID: "call_2", Name: "task_done", Args: map[string]any{}},
+	}
+	if !reflect.DeepEqual(chunks[0].FunctionCalls, want) {
+		t.Errorf("function calls = %+v, want %+v", chunks[0].FunctionCalls, want)
+	}
+}
+
+func TestOpenAIStreamHTTPError(t *testing.T) {
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"Rate limit reached","type":"requests"}}`)
+	})
+
+	_, err := collect(p, testRequest())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("err = %v, want an HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusTooManyRequests || httpErr.Message != "Rate limit reached" || httpErr.RetryAfter != 7*time.Second {
+		t.Errorf("HTTPError = %+v, want status 429, the API message and a 7s Retry-After", httpErr)
+	}
+	if kind := types.KindOf(Classify(err)); kind != types.ErrorQuota {
+		t.Errorf("kind = %q, want %q", kind, types.ErrorQuota)
+	}
+}
+
+func TestOpenAIStreamContentFilter(t *testing.T) {
+	p := newTestOpenAI(t, sseHandler(
+		`{"choices":[{"index":0,"delta":{"content":"Here is how"}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"content_filter"}]}`,
+		`[DONE]`,
+	))
+
+	chunks, err := collect(p, testRequest())
+	if len(chunks) != 1 || chunks[0].Text != "Here is how" {
+		t.Errorf("chunks = %+v, want the text streamed before the filter stopped it", chunks)
+	}
+	if kind := types.KindOf(err); kind != types.ErrorSafety {
+		t.Errorf("err = %v (kind %q), want a %q error", err, kind, types.ErrorSafety)
+	}
+}
+
+func TestOpenAIReasoningModel(t *testing.T) {
+	var body map[string]any
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		sseHandler(`{"choices":[{"index":0,"delta":{"content":"ok"}}]}`, `[DONE]`)(w, r)
+	})
+
+	req := testRequest()
+	req.Model = "o3-mini"
+	req.Generation.MaxOutputTokens = genai.Ptr(256)
+	if _, err := collect(p, req); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if body["max_completion_tokens"] != float64(256) {
+		t.Errorf("max_completion_tokens = %v, want 256", body["max_completion_tokens"])
+	}
+	for _, field := range []string{"max_tokens", "temperature"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("request sets %s, which reasoning models reject", field)
+		}
+	}
+
+	if err := p.ValidateGeneration("o3-mini", types.GenerationConfig{Temperature: genai.Ptr(float32(0.7))}); err == nil {
+		t.Error("ValidateGeneration accepted a temperature of 0.7 for a reasoning model")
+	}
+	if err := p.ValidateGeneration("o3-mini", types.GenerationConfig{Temperature: genai.Ptr(float32(1))}); err != nil {
+		t.Errorf("ValidateGeneration rejected a temperature of 1: %v", err)
+	}
+	if err := p.ValidateGeneration("gpt-4o-mini", types.GenerationConfig{Temperature: genai.Ptr(float32(0.7))}); err != nil {
+		t.Errorf("ValidateGeneration rejected a temperature for a chat model: %v", err)
+	}
+}
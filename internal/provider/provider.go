@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// DefaultProvider is the provider used when none is configured
+const DefaultProvider = "gemini"
+
+// DefaultContextWindow is assumed when a provider doesn't report a model's input limit
+const DefaultContextWindow = 8192
+
+// Gemini backends selectable through Options.Backend
+const (
+	BackendGeminiAPI = "gemini"
+	BackendVertexAI  = "vertex"
+)
+
+// Provider is a model backend that can answer questions
+type Provider interface {
+	// Name returns the name the provider is registered under
+	Name() string
+	// Endpoint identifies the server or account requests go to, so model lists and cached
+	// answers of two endpoints of the same provider are kept apart
+	Endpoint() string
+	// Stream generates a completion and yields chunks as they arrive
+	Stream(ctx context.Context, req *Request) iter.Seq2[*Chunk, error]
+	// ListModels returns the models available from the provider
+	ListModels(ctx context.Context) ([]Model, error)
+	// CountTokens returns the number of tokens the contents use for the given model
+	CountTokens(ctx context.Context, model string, contents []*genai.Content) (int, error)
+	// DefaultModel returns the model used when none is configured, empty when the provider has none
+	DefaultModel() string
+	// SupportsTools reports whether the provider honors Request.Tools
+	SupportsTools() bool
+	// ValidateGeneration reports sampling parameters the provider doesn't accept for the model
+	ValidateGeneration(model string, g types.GenerationConfig) error
+}
+
+// Request describes a single completion request
+type Request struct {
+	Model        string
+	SystemPrompt string
+	Contents     []*genai.Content
+	Generation   types.GenerationConfig
+	// Tools are functions the model may call instead of, or as well as, answering in text
+	Tools []*genai.FunctionDeclaration
+}
+
+// Chunk is a piece of a streamed completion
+type Chunk struct {
+	Text          string
+	FunctionCalls []*genai.FunctionCall
+	// Usage is set on chunks that report the request's token counts, the last one is the total
+	Usage *types.Usage
+}
+
+// Model describes a model offered by a provider
+type Model struct {
+	Name             string
+	DisplayName      string
+	InputTokenLimit  int
+	OutputTokenLimit int
+
+	// Capabilities as reported by the provider, or inferred from the model name when it doesn't say
+	Vision   bool
+	Tools    bool
+	Thinking bool
+}
+
+// Options holds the settings used to construct a provider
+type Options struct {
+	APIKey     string
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// Backend, Project and Location select between the Gemini API and Vertex AI
+	Backend  string
+	Project  string
+	Location string
+
+	// CassetteDir is where the replay provider reads recorded responses from
+	CassetteDir string
+}
+
+// Factory creates a provider from the given options
+type Factory func(ctx context.Context, opts Options) (Provider, error)
+
+var registry = make(map[string]Factory)
+
+// Register makes a provider available under the given name
+func Register(name string, factory Factory) {
+	if _, exists := registry[name]; exists {
+		panic("provider already registered: " + name)
+	}
+	registry[name] = factory
+}
+
+// New creates the provider registered under the given name
+func New(ctx context.Context, name string, opts Options) (Provider, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %v)", name, Names())
+	}
+	return factory(ctx, opts)
+}
+
+// Names returns the sorted names of all registered providers
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContentText joins the text parts of a content
+func ContentText(content *genai.Content) string {
+	var text strings.Builder
+	for _, part := range content.Parts {
+		text.WriteString(part.Text)
+	}
+	return text.String()
+}
+
+// hasInlineData reports whether content includes inline data such as images
+func hasInlineData(content *genai.Content) bool {
+	for _, part := range content.Parts {
+		if part.InlineData != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// imageTokenEstimate is roughly what a single image costs on common vision models
+const imageTokenEstimate = 258
+
+// estimateTokens approximates the token count of contents at roughly four characters per token
+func estimateTokens(contents []*genai.Content) int {
+	chars, images := 0, 0
+	for _, content := range contents {
+		chars += len(ContentText(content))
+		for _, part := range content.Parts {
+			if part.InlineData != nil {
+				images++
+			}
+		}
+	}
+	return (chars+3)/4 + images*imageTokenEstimate
+}
+
+// generationLimits describes which sampling parameters a provider accepts
+type generationLimits struct {
+	maxTemperature   float32
+	topK             bool
+	maxStopSequences int
+	maxCandidates    int
+}
+
+// validateGeneration checks sampling parameters against common ranges and a provider's limits
+func validateGeneration(name string, g types.GenerationConfig, limits generationLimits) error {
+	if g.Temperature != nil && (*g.Temperature < 0 || *g.Temperature > limits.maxTemperature) {
+		return fmt.Errorf("%s temperature must be between 0 and %g, got %g", name, limits.maxTemperature, *g.Temperature)
+	}
+	if g.TopP != nil && (*g.TopP <= 0 || *g.TopP > 1) {
+		return fmt.Errorf("top-p must be greater than 0 and at most 1, got %g", *g.TopP)
+	}
+	if g.TopK != nil {
+		if !limits.topK {
+			return fmt.Errorf("the %s provider doesn't support top-k", name)
+		}
+		if *g.TopK < 1 {
+			return fmt.Errorf("top-k must be at least 1, got %d", *g.TopK)
+		}
+	}
+	if g.MaxOutputTokens != nil && *g.MaxOutputTokens < 1 {
+		return fmt.Errorf("max output tokens must be at least 1, got %d", *g.MaxOutputTokens)
+	}
+	if len(g.StopSequences) > limits.maxStopSequences {
+		return fmt.Errorf("the %s provider accepts at most %d stop sequences, got %d", name, limits.maxStopSequences, len(g.StopSequences))
+	}
+	if g.CandidateCount != nil {
+		if *g.CandidateCount < 1 {
+			return fmt.Errorf("candidate count must be at least 1, got %d", *g.CandidateCount)
+		}
+		if *g.CandidateCount > limits.maxCandidates {
+			if limits.maxCandidates == 1 {
+				return fmt.Errorf("the %s provider only generates a single candidate", name)
+			}
+			return fmt.Errorf("the %s provider generates at most %d candidates, got %d", name, limits.maxCandidates, *g.CandidateCount)
+		}
+	}
+	return nil
+}
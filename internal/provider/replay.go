@@ -0,0 +1,292 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+	"google.golang.org/genai"
+)
+
+// ReplayProvider is the name of the provider that answers from recorded cassettes
+const ReplayProvider = "replay"
+
+func init() {
+	Register(ReplayProvider, newReplay)
+}
+
+// Cassette is a recorded response stream, stored under the hash of the request that produced it
+type Cassette struct {
+	Provider   string
+	Model      string
+	RecordedAt int64
+	// Request is kept for reading cassettes, only its hash is used to find them
+	Request *Request
+	Events  []CassetteEvent
+}
+
+// CassetteEvent is a chunk or the error that ended a recorded stream
+type CassetteEvent struct {
+	// OffsetMS is when the event arrived, in milliseconds after the request was sent
+	OffsetMS int64
+	Chunk    *Chunk `json:",omitempty"`
+	Error    string `json:",omitempty"`
+	// StatusCode is the HTTP status of a recorded API error, so rate limits replay as rate limits
+	StatusCode int `json:",omitempty"`
+	// Kind is the kind of a recorded error that isn't an API error, e.g. a blocked response
+	Kind types.ErrorKind `json:",omitempty"`
+}
+
+// RequestHash identifies a request independently of the provider it is sent to
+func RequestHash(req *Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayProvider answers requests with the cassettes recorded for them, without any network access
+type replayProvider struct {
+	dir string
+}
+
+// newReplay creates a replay provider that reads cassettes from Options.CassetteDir
+func newReplay(ctx context.Context, opts Options) (Provider, error) {
+	if opts.CassetteDir == "" {
+		return nil, fmt.Errorf("the replay provider needs a cassette directory")
+	}
+	return &replayProvider{dir: opts.CassetteDir}, nil
+}
+
+// Name returns the registered provider name
+func (r *replayProvider) Name() string {
+	return ReplayProvider
+}
+
+// Endpoint returns the cassette directory
+func (r *replayProvider) Endpoint() string {
+	return r.dir
+}
+
+// Stream replays the cassette recorded for the request, keeping the recorded timing
+func (r *replayProvider) Stream(ctx context.Context, req *Request) iter.Seq2[*Chunk, error] {
+	return func(yield func(*Chunk, error) bool) {
+		hash, err := RequestHash(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		cassette, err := readCassette(filepath.Join(r.dir, hash+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			yield(nil, fmt.Errorf("no cassette recorded for this request with model %s in %s (looked for %s.json), record one with --record", req.Model, r.dir, hash))
+			return
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		start := time.Now()
+		for _, event := range cassette.Events {
+			wait := time.Duration(event.OffsetMS)*time.Millisecond - time.Since(start)
+			if wait > 0 {
+				select {
+				case <-ctx.Done():
+					yield(nil, ctx.Err())
+					return
+				case <-time.After(wait):
+				}
+			}
+
+			if event.Error != "" {
+				switch {
+				case event.StatusCode != 0:
+					yield(nil, &HTTPError{StatusCode: event.StatusCode, Message: event.Error})
+				case event.Kind != "":
+					yield(nil, types.NewError(event.Kind, errors.New(event.Error)))
+				default:
+					yield(nil, errors.New(event.Error))
+				}
+				return
+			}
+			if event.Chunk != nil && !yield(event.Chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ListModels returns the models that cassettes were recorded with
+func (r *replayProvider) ListModels(ctx context.Context) ([]Model, error) {
+	cassettes, err := r.cassettes()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var models []Model
+	for _, cassette := range cassettes {
+		if cassette.Model == "" || seen[cassette.Model] {
+			continue
+		}
+		seen[cassette.Model] = true
+		models = append(models, Model{
+			Name:        cassette.Model,
+			DisplayName: "Recorded from " + cassette.Provider,
+			Tools:       true,
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// CountTokens estimates the tokens, since there is no tokenizer to ask
+func (r *replayProvider) CountTokens(ctx context.Context, model string, contents []*genai.Content) (int, error) {
+	return estimateTokens(contents), nil
+}
+
+// DefaultModel returns no model, a replayed request must name the model it was recorded with
+func (r *replayProvider) DefaultModel() string {
+	return ""
+}
+
+// SupportsTools reports true, cassettes recorded without tools only match requests without them
+func (r *replayProvider) SupportsTools() bool {
+	return true
+}
+
+// ValidateGeneration accepts everything, the parameters only select the cassette
+func (r *replayProvider) ValidateGeneration(model string, g types.GenerationConfig) error {
+	return nil
+}
+
+// cassettes reads every cassette in the directory
+func (r *replayProvider) cassettes() ([]*Cassette, error) {
+	files, err := filepath.Glob(filepath.Join(r.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cassettes: %w", err)
+	}
+
+	cassettes := make([]*Cassette, 0, len(files))
+	for _, file := range files {
+		cassette, err := readCassette(file)
+		if err != nil {
+			return nil, err
+		}
+		cassettes = append(cassettes, cassette)
+	}
+	return cassettes, nil
+}
+
+// readCassette loads a cassette file
+func readCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", filepath.Base(path), err)
+	}
+	return &cassette, nil
+}
+
+// recordingProvider records every stream of the wrapped provider to a cassette
+type recordingProvider struct {
+	Provider
+	dir string
+}
+
+// WithRecorder wraps a provider so every response it streams is saved as a cassette in dir,
+// for the replay provider to answer the same request with later
+func WithRecorder(p Provider, dir string) Provider {
+	return &recordingProvider{Provider: p, dir: dir}
+}
+
+// Stream streams from the wrapped provider and writes the cassette once the stream ends
+func (r *recordingProvider) Stream(ctx context.Context, req *Request) iter.Seq2[*Chunk, error] {
+	return func(yield func(*Chunk, error) bool) {
+		cassette := &Cassette{
+			Provider:   r.Provider.Name(),
+			Model:      req.Model,
+			RecordedAt: time.Now().Unix(),
+			Request:    req,
+		}
+		start := time.Now()
+
+		var failure error
+		for chunk, err := range r.Provider.Stream(ctx, req) {
+			event := CassetteEvent{OffsetMS: time.Since(start).Milliseconds(), Chunk: chunk}
+			if err != nil {
+				failure = err
+				event = errorEvent(event.OffsetMS, err)
+			}
+			cassette.Events = append(cassette.Events, event)
+
+			// Stopping at an error is how a failed stream ends, stopping at a chunk cuts it short
+			if !yield(chunk, err) && err == nil {
+				return
+			}
+			if err != nil {
+				break
+			}
+		}
+
+		// A cancelled stream isn't what the provider would have sent
+		if ctx.Err() != nil {
+			return
+		}
+		if err := r.save(cassette); err != nil && failure == nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// save writes a cassette to the directory, replacing an earlier recording of the same request
+func (r *recordingProvider) save(cassette *Cassette) error {
+	hash, err := RequestHash(cassette.Request)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, hash+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return nil
+}
+
+// errorEvent records an error, keeping the status and message of API errors apart so they replay the same way
+func errorEvent(offsetMS int64, err error) CassetteEvent {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return CassetteEvent{OffsetMS: offsetMS, Error: httpErr.Message, StatusCode: httpErr.StatusCode}
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return CassetteEvent{OffsetMS: offsetMS, Error: apiErr.Message, StatusCode: apiErr.Code}
+	}
+	event := CassetteEvent{OffsetMS: offsetMS, Error: err.Error()}
+	if kind := types.KindOf(err); kind != types.ErrorGeneral {
+		event.Kind = kind
+	}
+	return event
+}
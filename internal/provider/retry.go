@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// RetryPolicy controls how failed requests are retried before the first chunk arrives
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	MaxAttempts int
+	// BaseDelay is doubled after every attempt, up to MaxDelay, and jittered
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxRetryAfter is the longest Retry-After that is waited for, longer ones fail right away
+	MaxRetryAfter time.Duration
+	// OnRetry is called before waiting to retry, e.g. to tell the user
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// DefaultRetryPolicy retries rate limits and server errors three times over a few seconds
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:   4,
+	BaseDelay:     time.Second,
+	MaxDelay:      30 * time.Second,
+	MaxRetryAfter: time.Minute,
+}
+
+// retryingProvider retries streams that fail with a retryable error before yielding anything
+type retryingProvider struct {
+	Provider
+	policy RetryPolicy
+}
+
+// WithRetry wraps a provider so streams that fail with a rate limit or server error are retried
+// with exponential backoff, as long as nothing has been streamed yet
+func WithRetry(p Provider, policy RetryPolicy) Provider {
+	if policy.MaxAttempts <= 1 {
+		return p
+	}
+	return &retryingProvider{Provider: p, policy: policy}
+}
+
+// Stream streams from the wrapped provider, retrying failures that happen before the first chunk
+func (r *retryingProvider) Stream(ctx context.Context, req *Request) iter.Seq2[*Chunk, error] {
+	return func(yield func(*Chunk, error) bool) {
+		for attempt := 1; ; attempt++ {
+			started := false
+			var failure error
+
+			for chunk, err := range r.Provider.Stream(ctx, req) {
+				if err != nil {
+					failure = err
+					break
+				}
+				started = true
+				if !yield(chunk, nil) {
+					return
+				}
+			}
+			if failure == nil {
+				return
+			}
+
+			// Once text is on screen a retry would repeat it, so only retry before that
+			delay, retryable := r.delay(attempt, failure)
+			if started || !retryable || attempt >= r.policy.MaxAttempts || ctx.Err() != nil {
+				yield(nil, failure)
+				return
+			}
+
+			if r.policy.OnRetry != nil {
+				r.policy.OnRetry(attempt, delay, failure)
+			}
+			select {
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+				return
+			case <-time.After(delay):
+			}
+		}
+	}
+}
+
+// delay returns how long to wait before the next attempt and whether err is worth retrying at all
+func (r *retryingProvider) delay(attempt int, err error) (time.Duration, bool) {
+	retryAfter, retryable := RetryAfter(err)
+	if !retryable {
+		return 0, false
+	}
+	if retryAfter > 0 {
+		return retryAfter, retryAfter <= r.policy.MaxRetryAfter
+	}
+
+	// Equal jitter, a random delay between half and all of the exponential backoff, spreads out
+	// retries from parallel runs while still backing off
+	backoff := min(r.policy.BaseDelay<<(attempt-1), r.policy.MaxDelay)
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// RetryAfter reports whether err is a rate limit or server error that is worth retrying,
+// and how long the server asked to wait if it said
+func RetryAfter(err error) (time.Duration, bool) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.RetryAfter, retryableStatus(httpErr.StatusCode)
+	}
+
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return geminiRetryDelay(apiErr), retryableStatus(apiErr.Code)
+	}
+	return 0, false
+}
+
+// Unavailable reports whether err means the model can't answer right now or at all, because it
+// is rate limited, unknown to the provider, failing or unreachable
+func Unavailable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusNotFound || retryableStatus(httpErr.StatusCode)
+	}
+
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusNotFound || retryableStatus(apiErr.Code)
+	}
+
+	// Connection failures, but not a request that was cancelled or timed out on our side
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// retryableStatus reports whether a status code means the request may succeed if sent again
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// geminiRetryDelay reads the delay from the RetryInfo detail the Gemini API attaches to rate limit errors
+func geminiRetryDelay(err genai.APIError) time.Duration {
+	for _, detail := range err.Details {
+		kind, _ := detail["@type"].(string)
+		if !strings.HasSuffix(kind, "RetryInfo") {
+			continue
+		}
+		value, _ := detail["retryDelay"].(string)
+		if delay, err := time.ParseDuration(value); err == nil {
+			return delay
+		}
+	}
+	return 0
+}
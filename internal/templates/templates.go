@@ -0,0 +1,177 @@
+package templates
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Extension is the file extension of prompt templates
+const Extension = ".tmpl"
+
+// Variable is a value a template expects to be given
+type Variable struct {
+	Name        string
+	Description string
+	Default     string
+}
+
+// Template is a reusable prompt loaded from the templates directory
+type Template struct {
+	Name        string
+	Description string
+	Vars        []Variable
+	Path        string
+	tmpl        *template.Template
+}
+
+// List loads all templates in dir, sorted by name
+func List(dir string) ([]*Template, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+Extension))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	sort.Strings(files)
+
+	templates := make([]*Template, 0, len(files))
+	for _, file := range files {
+		t, err := parseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// Load loads the template with the given name from dir
+func Load(dir, name string) (*Template, error) {
+	path := filepath.Join(dir, strings.TrimSuffix(name, Extension)+Extension)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("template %q not found in %s, run `oracle templates list` to see available templates", name, dir)
+	}
+	return parseFile(path)
+}
+
+// Missing returns the declared variables that have neither a value nor a default
+func (t *Template) Missing(values map[string]string) []Variable {
+	var missing []Variable
+	for _, v := range t.Vars {
+		if _, ok := values[v.Name]; !ok && v.Default == "" {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// Render executes the template with the given values, filling in defaults for the rest
+func (t *Template) Render(values map[string]string) (string, error) {
+	data := make(map[string]string, len(t.Vars))
+	for _, v := range t.Vars {
+		if v.Default != "" {
+			data[v.Name] = v.Default
+		}
+	}
+	for name, value := range values {
+		if !t.declares(name) {
+			return "", fmt.Errorf("template %q has no variable %q (declared: %s)", t.Name, name, strings.Join(t.varNames(), ", "))
+		}
+		data[name] = value
+	}
+
+	var out strings.Builder
+	if err := t.tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", t.Name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// declares reports whether the template declares a variable called name
+func (t *Template) declares(name string) bool {
+	for _, v := range t.Vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// varNames returns the names of the declared variables
+func (t *Template) varNames() []string {
+	names := make([]string, len(t.Vars))
+	for i, v := range t.Vars {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// parseFile reads a template file, its header and its body
+func parseFile(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), Extension)
+	t := &Template{Name: name, Path: path}
+	if err := t.parseHeader(string(data)); err != nil {
+		return nil, fmt.Errorf("invalid header in template %q: %w", name, err)
+	}
+
+	// Undeclared or missing variables are errors rather than silently rendering "<no value>"
+	t.tmpl, err = template.New(name).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %q: %w", name, err)
+	}
+	return t, nil
+}
+
+// parseHeader reads the description and variables from the comment that opens a template:
+//
+//	{{/*
+//	description: Debug a crashing Kubernetes workload
+//	var: ns | Namespace the workload runs in | default
+//	var: pod | Name of the failing pod
+//	*/}}
+func (t *Template) parseHeader(text string) error {
+	text = strings.TrimLeft(text, " \t\r\n")
+	if !strings.HasPrefix(text, "{{/*") {
+		return nil
+	}
+	end := strings.Index(text, "*/}}")
+	if end == -1 {
+		return fmt.Errorf("header comment is not closed")
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text[len("{{/*"):end]))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "description":
+			t.Description = value
+		case "var":
+			fields := strings.Split(value, "|")
+			v := Variable{Name: strings.TrimSpace(fields[0])}
+			if v.Name == "" {
+				return fmt.Errorf("variable without a name")
+			}
+			if len(fields) > 1 {
+				v.Description = strings.TrimSpace(fields[1])
+			}
+			if len(fields) > 2 {
+				v.Default = strings.TrimSpace(strings.Join(fields[2:], "|"))
+			}
+			t.Vars = append(t.Vars, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ShowAgentStep displays which step of an agent run is starting
+func ShowAgentStep(step, maxSteps int) {
+	current.AgentStep(step, maxSteps)
+}
+
+// ShowAgentDone displays the summary the model gave when ending an agent run
+func ShowAgentDone(summary string, success bool) {
+	current.AgentDone(summary, success)
+}
+
+// AgentStep displays which step of an agent run is starting
+func (p *prettyPresenter) AgentStep(step, maxSteps int) {
+	fmt.Println(lipgloss.NewStyle().Foreground(slate).Bold(true).Render(fmt.Sprintf("── Step %d/%d ──", step, maxSteps)))
+}
+
+// AgentDone displays the summary the model gave when ending an agent run
+func (p *prettyPresenter) AgentDone(summary string, success bool) {
+	color, title := green, "✓ Task done"
+	if !success {
+		color, title = statusErrorColor, "✗ Task not completed"
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 2).
+		Render(lipgloss.NewStyle().Foreground(color).Bold(true).Render(title) + "\n\n" + summary)
+
+	fmt.Println(box)
+}
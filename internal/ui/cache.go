@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/internal/cache"
+)
+
+// ShowCachedNotice tells the user a response came from the cache rather than the model
+func ShowCachedNotice(providerName, model string, createdAt time.Time) {
+	current.Cached(providerName, model, createdAt)
+}
+
+// Cached tells the user a response came from the cache rather than the model
+func (p *prettyPresenter) Cached(providerName, model string, createdAt time.Time) {
+	fmt.Println(lipgloss.NewStyle().Foreground(slate).Render(cachedNotice(createdAt)))
+}
+
+// cachedNotice says how old a cached response is and how to skip the cache
+func cachedNotice(createdAt time.Time) string {
+	return fmt.Sprintf("Cached response from %s ago, use --refresh to ask again", formatAge(time.Since(createdAt)))
+}
+
+// ShowCacheStats displays what the response cache holds and its limits
+func ShowCacheStats(dir string, stats *cache.Stats, enabled bool, ttl time.Duration, maxSize int64) {
+	fmt.Println(HeaderStyle.Render("Response cache"))
+
+	status := "enabled"
+	if !enabled {
+		status = "disabled"
+	}
+
+	lines := []string{
+		"Location: " + dir,
+		"Status:   " + status,
+		fmt.Sprintf("Entries:  %d (%d expired)", stats.Entries, stats.Expired),
+		fmt.Sprintf("Size:     %s of %s", formatBytes(stats.Size), formatBytes(maxSize)),
+		"TTL:      " + ttl.String(),
+	}
+	if stats.Entries > 0 {
+		lines = append(lines,
+			"Oldest:   "+stats.Oldest.Format("2006-01-02 15:04"),
+			"Newest:   "+stats.Newest.Format("2006-01-02 15:04"))
+	}
+
+	for _, line := range lines {
+		fmt.Println(QuestionStyle.Render(line))
+	}
+}
+
+// formatAge formats a duration coarsely, e.g. "3m" or "2h"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
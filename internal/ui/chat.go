@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ShowChatWelcome displays the banner shown when a chat session starts
+func ShowChatWelcome(providerName, model string) {
+	welcome := lipgloss.NewStyle().
+		Foreground(blue).
+		Bold(true).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(gold).
+		Padding(0, 2).
+		MarginBottom(1).
+		Render(fmt.Sprintf("🔮 Oracle chat (%s · %s)\n\nType /help for commands, Ctrl-D to exit.", providerName, model))
+
+	fmt.Println(welcome)
+}
+
+// ShowChatPrompt displays the prompt for the next user message
+func ShowChatPrompt() {
+	fmt.Print(lipgloss.NewStyle().Foreground(yellow).Bold(true).Render("You: "))
+}
+
+// ShowChatHelp displays the available slash commands
+func ShowChatHelp() {
+	help := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(blue).
+		Padding(0, 2).
+		Render(`/model [name]  Show or switch the model
+/clear         Start a fresh conversation
+/save [file]   Save the conversation as markdown
+/exec          Run commands from the last answer
+/help          Show this help
+/exit          Leave the chat (or press Ctrl-D)`)
+
+	fmt.Println(help)
+}
+
+// ShowChatGoodbye displays the message shown when a chat session ends
+func ShowChatGoodbye() {
+	fmt.Println(QuestionStyle.Render("Goodbye!"))
+}
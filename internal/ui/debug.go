@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// ShowDebugSettings prints the effective provider, model and generation parameters to stderr
+func ShowDebugSettings(provider, model string, g types.GenerationConfig) {
+	rows := [][2]string{
+		{"provider", provider},
+		{"model", model},
+		{"temperature", formatFloat(g.Temperature)},
+		{"top_p", formatFloat(g.TopP)},
+		{"top_k", formatInt(g.TopK)},
+		{"max_output_tokens", formatInt(g.MaxOutputTokens)},
+		{"stop_sequences", formatStrings(g.StopSequences)},
+		{"seed", formatInt(g.Seed)},
+		{"candidate_count", formatInt(g.CandidateCount)},
+	}
+
+	style := lipgloss.NewStyle().Foreground(slate)
+	for _, row := range rows {
+		fmt.Fprintln(os.Stderr, style.Render(fmt.Sprintf("debug: %-18s %s", row[0], row[1])))
+	}
+}
+
+// formatFloat formats an optional float, or "default" when it's unset
+func formatFloat(v *float32) string {
+	if v == nil {
+		return "default"
+	}
+	return strconv.FormatFloat(float64(*v), 'g', -1, 32)
+}
+
+// formatInt formats an optional integer, or "default" when it's unset
+func formatInt(v *int) string {
+	if v == nil {
+		return "default"
+	}
+	return strconv.Itoa(*v)
+}
+
+// formatStrings formats a list of quoted strings, or "none" when it's empty
+func formatStrings(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
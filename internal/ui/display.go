@@ -2,12 +2,17 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/internal/attach"
+	"github.com/simplyzetax/oracle/pkg/types"
 )
 
 // Color palette
@@ -113,7 +118,7 @@ func ConfirmExecution(command string) bool {
 				Negative("No").
 				Value(&confirm),
 		),
-	)
+	).WithOutput(promptOutput())
 	err := form.Run()
 	if err != nil {
 		return false
@@ -132,11 +137,11 @@ func ConfirmContinueOnError() bool {
 				Negative("No, stop").
 				Value(&continueExec),
 		),
-	)
+	).WithOutput(promptOutput())
 	err := form.Run()
 	if err != nil {
 		// Fallback to basic text confirmation if huh fails
-		fmt.Print(lipgloss.NewStyle().Foreground(gold).Render("Continue with remaining commands? (y/N): "))
+		fmt.Fprint(promptOutput(), lipgloss.NewStyle().Foreground(gold).Render("Continue with remaining commands? (y/N): "))
 		var responseText string
 		_, _ = fmt.Scanln(&responseText)
 		responseText = strings.ToLower(strings.TrimSpace(responseText))
@@ -152,35 +157,136 @@ func ShowCommandSuggestion(command string) {
 		lipgloss.NewStyle().Foreground(pearl).Render(command))
 }
 
+// ShowCommandProposal displays a proposed command along with what it does and how risky it is
+func ShowCommandProposal(proposal types.CommandProposal) {
+	current.CommandProposal(proposal)
+}
+
+// ShowCommandDetected reports a command found in a response
+func ShowCommandDetected(proposal types.CommandProposal) {
+	current.CommandDetected(proposal)
+}
+
+// ShowExecStarted reports that a confirmed command is starting
+func ShowExecStarted(command string) {
+	current.ExecStarted(command)
+}
+
+// ShowExecFinished reports how a command ended
+func ShowExecFinished(command string, exitCode int, err error) {
+	current.ExecFinished(command, exitCode, err)
+}
+
+// CommandProposal displays a proposed command along with what it does and how risky it is
+func (p *prettyPresenter) CommandProposal(proposal types.CommandProposal) {
+	ShowCommandSuggestion(proposal.Command)
+
+	var details []string
+	if proposal.Description != "" {
+		details = append(details, proposal.Description)
+	}
+	if proposal.WorkingDir != "" {
+		details = append(details, "in "+proposal.WorkingDir)
+	}
+	if len(details) > 0 {
+		fmt.Println("  " + lipgloss.NewStyle().Foreground(slate).Render(strings.Join(details, " · ")))
+	}
+
+	var badges []string
+	switch proposal.Risk {
+	case types.RiskHigh:
+		badges = append(badges, lipgloss.NewStyle().Foreground(statusErrorColor).Bold(true).Render("high risk"))
+	case types.RiskMedium:
+		badges = append(badges, lipgloss.NewStyle().Foreground(gold).Bold(true).Render("medium risk"))
+	}
+	if proposal.NeedsSudo {
+		badges = append(badges, lipgloss.NewStyle().Foreground(orange).Bold(true).Render("needs sudo"))
+	}
+	if len(badges) > 0 {
+		fmt.Println("  " + strings.Join(badges, " "))
+	}
+}
+
+// CommandDetected shows nothing, the command is already part of the response
+func (p *prettyPresenter) CommandDetected(proposal types.CommandProposal) {}
+
+// ExecStarted shows nothing, the command's own output follows
+func (p *prettyPresenter) ExecStarted(command string) {}
+
+// ExecFinished notes commands that failed
+func (p *prettyPresenter) ExecFinished(command string, exitCode int, err error) {
+	if err != nil {
+		fmt.Printf("Command failed: %s\n", command)
+	}
+}
+
+// CommandOutput lets commands write to the terminal
+func (p *prettyPresenter) CommandOutput() io.Writer {
+	return os.Stdout
+}
+
 // ShowExecutionStatus displays execution status messages
 func ShowExecutionStatus(message string, statusType string) {
+	current.Status(message, statusType)
+}
+
+// Status displays execution status messages
+func (p *prettyPresenter) Status(message string, statusType string) {
 	var style lipgloss.Style
-	var prefix string
 
 	switch statusType {
 	case "success":
 		style = lipgloss.NewStyle().Foreground(green).Bold(true)
-		prefix = "Success:"
 	case "error":
 		style = lipgloss.NewStyle().Foreground(statusErrorColor).Bold(true)
-		prefix = "Error:"
 	case "executing":
 		style = lipgloss.NewStyle().Foreground(orange).Bold(true)
-		prefix = "Executing:"
 	case "warning":
 		style = lipgloss.NewStyle().Foreground(gold).Bold(true)
-		prefix = "Warning:"
 	default: // "info" or any other type
 		style = lipgloss.NewStyle().Foreground(blue).Bold(true)
-		prefix = "Info:"
 	}
-	fmt.Println(style.Render(fmt.Sprintf("%s %s", prefix, message)))
+	fmt.Println(style.Render(fmt.Sprintf("%s %s", statusPrefix(statusType), message)))
 }
 
-// ShowError displays a general error message and exits
-func ShowError(message string) {
-	fmt.Println(ErrorStyle.Render("Error: " + message))
-	os.Exit(1)
+// statusPrefix returns the label a status message starts with
+func statusPrefix(statusType string) string {
+	switch statusType {
+	case "success":
+		return "Success:"
+	case "error":
+		return "Error:"
+	case "executing":
+		return "Executing:"
+	case "warning":
+		return "Warning:"
+	default:
+		return "Info:"
+	}
+}
+
+// ShowError displays the error that ended the command
+func ShowError(err error) {
+	current.Error(err)
+}
+
+// Error displays the error that ended the command, a cancellation is only a warning
+func (p *prettyPresenter) Error(err error) {
+	if types.KindOf(err) == types.ErrorCancelled {
+		p.Status(errorMessage(err), "warning")
+		return
+	}
+	fmt.Println(ErrorStyle.Render("Error: " + errorMessage(err)))
+}
+
+// errorMessage capitalizes the first letter of an error, since Go errors start in lowercase
+func errorMessage(err error) string {
+	message := err.Error()
+	if message == "" {
+		return message
+	}
+	r, size := utf8.DecodeRuneInString(message)
+	return string(unicode.ToUpper(r)) + message[size:]
 }
 
 // ShowSuccess displays a general success message
@@ -190,15 +296,50 @@ func ShowSuccess(message string) {
 
 // StartResponseStream initializes the response display
 func StartResponseStream() {
-	// Simple prefix for the AI's response stream
-	fmt.Print(lipgloss.NewStyle().Foreground(pearl).Bold(true).Render("A: "))
+	current.StartResponse()
+}
+
+// StreamResponseText adds streamed response text to the display
+func StreamResponseText(text string) {
+	current.ResponseText(text)
 }
 
 // EndResponseStream finalizes the response display
 func EndResponseStream() {
+	current.EndResponse()
+}
+
+// prettyPresenter renders output for a terminal, with styles and live markdown
+type prettyPresenter struct {
+	stream *MarkdownStream
+}
+
+// StartResponse prints the response header and starts rendering markdown
+func (p *prettyPresenter) StartResponse() {
+	// Simple header for the AI's response stream, on its own line so redraws never touch it
+	fmt.Println(lipgloss.NewStyle().Foreground(pearl).Bold(true).Render("A:"))
+	p.stream = NewMarkdownStream()
+}
+
+// ResponseText renders streamed markdown, committing finished blocks
+func (p *prettyPresenter) ResponseText(text string) {
+	if p.stream != nil {
+		p.stream.Write(text)
+	}
+}
+
+// EndResponse commits the rest of the response
+func (p *prettyPresenter) EndResponse() {
+	if p.stream != nil {
+		p.stream.Close()
+		p.stream = nil
+	}
 	fmt.Println() // Just a newline for spacing
 }
 
+// Finish has nothing left to print, everything was shown as it happened
+func (p *prettyPresenter) Finish() {}
+
 // ShowFirstRunWelcome displays a welcome message for first-time users
 func ShowFirstRunWelcome() {
 	welcome := lipgloss.NewStyle().
@@ -281,3 +422,52 @@ func ShowAliasSetupSuccess() {
 
 	fmt.Println(success)
 }
+
+// ShowAttachments lists the context and images attached to a question
+func ShowAttachments(attachments []*attach.Attachment, images []*attach.Image, dropped []string) {
+	current.Attachments(attachments, images, dropped)
+}
+
+// Attachments lists the context and images attached to a question
+func (p *prettyPresenter) Attachments(attachments []*attach.Attachment, images []*attach.Image, dropped []string) {
+	fmt.Println(lipgloss.NewStyle().Foreground(yellow).Bold(true).Render("Attached:"))
+
+	for _, a := range attachments {
+		fmt.Printf("  %s %s %s\n",
+			lipgloss.NewStyle().Foreground(green).Bold(true).Render("•"),
+			a.Name,
+			lipgloss.NewStyle().Foreground(slate).Render("("+attachmentDetail(a)+")"))
+	}
+
+	for _, image := range images {
+		fmt.Printf("  %s %s %s\n",
+			lipgloss.NewStyle().Foreground(green).Bold(true).Render("•"),
+			image.Name,
+			lipgloss.NewStyle().Foreground(slate).Render("("+imageDetail(image)+")"))
+	}
+
+	for _, name := range dropped {
+		fmt.Printf("  %s %s %s\n",
+			lipgloss.NewStyle().Foreground(statusErrorColor).Bold(true).Render("✗"),
+			name,
+			lipgloss.NewStyle().Foreground(slate).Render("(dropped to fit the token budget)"))
+	}
+}
+
+// attachmentDetail describes the size of an attachment, e.g. "12 lines, truncated"
+func attachmentDetail(a *attach.Attachment) string {
+	lines := strings.Count(strings.TrimSuffix(a.Content, "\n"), "\n") + 1
+	detail := fmt.Sprintf("%d lines", lines)
+	if lines == 1 {
+		detail = "1 line"
+	}
+	if a.Truncated {
+		detail += ", truncated"
+	}
+	return detail
+}
+
+// imageDetail describes the format and size of an image
+func imageDetail(image *attach.Image) string {
+	return fmt.Sprintf("%s, %d KB", image.MIMEType, (len(image.Data)+1023)/1024)
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// ShowHistoryList displays a one-line summary per history entry
+func ShowHistoryList(entries []*types.HistoryEntry) {
+	if len(entries) == 0 {
+		fmt.Println(QuestionStyle.Render("No history entries found"))
+		return
+	}
+
+	idStyle := lipgloss.NewStyle().Foreground(gold)
+	metaStyle := lipgloss.NewStyle().Foreground(slate)
+
+	for _, entry := range entries {
+		asked := time.Unix(entry.Question.Timestamp, 0).Format("2006-01-02 15:04")
+		fmt.Printf("%s  %s  %s\n",
+			idStyle.Render(entry.ID),
+			metaStyle.Render(asked+"  "+entry.Question.Model),
+			truncate(firstLine(entry.Question.Text), 60))
+	}
+}
+
+// ShowHistoryEntry displays a full history entry with its response and commands
+func ShowHistoryEntry(entry *types.HistoryEntry) {
+	asked := time.Unix(entry.Question.Timestamp, 0).Format("2006-01-02 15:04:05")
+	fmt.Println(HeaderStyle.Render(fmt.Sprintf("%s · %s · %s/%s", entry.ID, asked, entry.Provider, entry.Response.Model)))
+	if entry.Response.Model != "" && entry.Response.Model != entry.Question.Model {
+		fmt.Println(QuestionStyle.Render("Fallback for: " + entry.Question.Model))
+	}
+	if entry.SessionID != "" && entry.SessionID != entry.ID {
+		fmt.Println(QuestionStyle.Render("Session: " + entry.SessionID))
+	}
+	fmt.Println(QuestionStyle.Render("Q: " + entry.Question.Text))
+	for _, a := range entry.Question.Attachments {
+		fmt.Println(QuestionStyle.Render(fmt.Sprintf("Attached: %s (%d KB)", a.Name, (a.Bytes+1023)/1024)))
+	}
+
+	RenderFinalResponse(entry.Response.Text)
+
+	if len(entry.Commands) == 0 {
+		return
+	}
+
+	fmt.Println(lipgloss.NewStyle().Foreground(yellow).Bold(true).Render("Commands:"))
+	for _, cmd := range entry.Commands {
+		status := lipgloss.NewStyle().Foreground(slate).Render("not run")
+		if cmd.Executed {
+			color := green
+			if cmd.ExitCode != 0 {
+				color = statusErrorColor
+			}
+			status = lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("exit %d", cmd.ExitCode))
+		}
+		fmt.Printf("  %s %s  %s\n",
+			lipgloss.NewStyle().Foreground(green).Bold(true).Render("→"),
+			cmd.Command,
+			status)
+	}
+}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		return text[:idx]
+	}
+	return text
+}
+
+// truncate shortens text to at most n runes, adding an ellipsis when cut
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -1,24 +1,35 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/pkg/types"
+	"golang.org/x/term"
 )
 
+// IsInteractive reports whether stdin is a terminal, so prompts can be shown
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
 // PromptForQuestion prompts the user to enter a question interactively using gum
-func PromptForQuestion() string {
+func PromptForQuestion() (string, error) {
 	// Use gum input via command execution for compatibility
 	cmd := exec.Command("gum", "input", "--placeholder", "Enter your question here...", "--prompt", "What would you like to ask? ")
 	cmd.Stderr = os.Stderr
 
 	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 130 {
+		return "", types.NewError(types.ErrorCancelled, errors.New("no question entered"))
+	}
 	if err != nil {
-		ShowError("Failed to get input: " + err.Error())
-		return ""
+		return "", fmt.Errorf("failed to get input: %w", err)
 	}
 
 	// Remove trailing newline
@@ -27,27 +38,25 @@ func PromptForQuestion() string {
 		question = question[:len(question)-1]
 	}
 
-	return question
+	return question, nil
 }
 
 // PromptForAPIKey prompts the user to enter their Google AI API key
-func PromptForAPIKey() string {
-	fmt.Print("Enter your Google AI API Key (get it from https://ai.google.dev/gemini-api/docs/api-key): ")
+func PromptForAPIKey() (string, error) {
+	fmt.Fprint(promptOutput(), "Enter your Google AI API Key (get it from https://ai.google.dev/gemini-api/docs/api-key): ")
 
 	var apiKey string
 	_, err := fmt.Scanln(&apiKey)
 	if err != nil {
-		ShowError("Failed to read API key: " + err.Error())
-		return ""
+		return "", fmt.Errorf("failed to read API key: %w", err)
 	}
 
 	apiKey = strings.TrimSpace(apiKey)
 	if apiKey == "" {
-		ShowError("API key cannot be empty")
-		return ""
+		return "", errors.New("API key cannot be empty")
 	}
 
-	return apiKey
+	return apiKey, nil
 }
 
 // ShowAPIKeySetupSuccess displays success message for API key setup
@@ -62,7 +71,7 @@ func ShowAPIKeySetupSuccess() {
 		MarginBottom(1).
 		Render("🔑 API Key configured successfully!\n\nYour Google AI API key has been saved securely to ~/.oracle/config.json\nYou're now ready to start asking questions!")
 
-	fmt.Println(success)
+	fmt.Fprintln(promptOutput(), success)
 }
 
 // ShowAPIKeyPrompt displays a message about needing to set up the API key
@@ -77,5 +86,5 @@ func ShowAPIKeyPrompt() {
 		MarginBottom(1).
 		Render("🔑 API Key Required\n\nTo use Oracle, you need a Google AI API key.\nThis will be saved securely in your local configuration.")
 
-	fmt.Println(prompt)
+	fmt.Fprintln(promptOutput(), prompt)
 }
@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// jsonUsage is the token usage of a response in the json and ndjson output
+type jsonUsage struct {
+	PromptTokens   int `json:"prompt_tokens"`
+	ResponseTokens int `json:"response_tokens"`
+	ThinkingTokens int `json:"thinking_tokens"`
+	TotalTokens    int `json:"total_tokens"`
+}
+
+// jsonCommand is a command found in the response and what became of it
+type jsonCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+	WorkingDir  string `json:"working_dir,omitempty"`
+	Risk        string `json:"risk,omitempty"`
+	NeedsSudo   bool   `json:"needs_sudo"`
+	Executed    bool   `json:"executed"`
+	ExitCode    *int   `json:"exit_code,omitempty"`
+}
+
+// jsonResult is the object the json output mode prints once the question is handled
+type jsonResult struct {
+	Answer   string        `json:"answer"`
+	Provider string        `json:"provider,omitempty"`
+	Model    string        `json:"model,omitempty"`
+	Cached   bool          `json:"cached"`
+	Usage    *jsonUsage    `json:"usage,omitempty"`
+	Cost     *float64      `json:"cost,omitempty"`
+	Commands []jsonCommand `json:"commands"`
+	// Summary and Success are set when an agent run declared its task done
+	Summary  string   `json:"summary,omitempty"`
+	Success  *bool    `json:"success,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	// ErrorKind is the kind of Error, matching the exit code
+	ErrorKind types.ErrorKind `json:"error_kind,omitempty"`
+}
+
+// newJSONUsage converts token usage for the json and ndjson output
+func newJSONUsage(u types.Usage) *jsonUsage {
+	return &jsonUsage{
+		PromptTokens:   u.PromptTokens,
+		ResponseTokens: u.ResponseTokens,
+		ThinkingTokens: u.ThinkingTokens,
+		TotalTokens:    u.Total(),
+	}
+}
+
+// newJSONCommand converts a command proposal for the json and ndjson output
+func newJSONCommand(proposal types.CommandProposal) jsonCommand {
+	return jsonCommand{
+		Command:     proposal.Command,
+		Description: proposal.Description,
+		WorkingDir:  proposal.WorkingDir,
+		Risk:        proposal.Risk,
+		NeedsSudo:   proposal.NeedsSudo,
+	}
+}
+
+// writeJSON prints a value as a single line of JSON on stdout
+func writeJSON(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: failed to encode output:", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// jsonPresenter collects the answer, model, usage and commands and prints them as one JSON object
+type jsonPresenter struct {
+	stderrPresenter
+	result   jsonResult
+	answer   strings.Builder
+	printed  bool
+	anyUsage bool
+}
+
+// Status prints the message to stderr and keeps warnings and errors for the result
+func (p *jsonPresenter) Status(message, statusType string) {
+	p.stderrPresenter.Status(message, statusType)
+	if statusType == "warning" || statusType == "error" {
+		p.result.Warnings = append(p.result.Warnings, message)
+	}
+}
+
+// Error prints the result with the error that ended the command
+func (p *jsonPresenter) Error(err error) {
+	p.result.Error = errorMessage(err)
+	p.result.ErrorKind = types.KindOf(err)
+	p.Finish()
+}
+
+// StartResponse separates the response from an earlier one in the same run
+func (p *jsonPresenter) StartResponse() {
+	if p.answer.Len() > 0 {
+		p.answer.WriteString("\n\n")
+	}
+}
+
+// ResponseText adds streamed text to the answer
+func (p *jsonPresenter) ResponseText(text string) {
+	p.answer.WriteString(text)
+}
+
+// EndResponse has nothing to do, the answer is printed by Finish
+func (p *jsonPresenter) EndResponse() {}
+
+// Cached notes where the cached response came from
+func (p *jsonPresenter) Cached(providerName, model string, createdAt time.Time) {
+	p.stderrPresenter.Cached(providerName, model, createdAt)
+	p.result.Cached = true
+	p.result.Provider = providerName
+	p.result.Model = model
+}
+
+// Usage records the model that answered, adding up the tokens of every response in the run
+func (p *jsonPresenter) Usage(providerName, model string, u *types.Usage, cost float64, priced bool) {
+	p.result.Provider = providerName
+	p.result.Model = model
+	if u == nil {
+		return
+	}
+
+	total := *u
+	if p.result.Usage != nil {
+		total.PromptTokens += p.result.Usage.PromptTokens
+		total.ResponseTokens += p.result.Usage.ResponseTokens
+		total.ThinkingTokens += p.result.Usage.ThinkingTokens
+	}
+	p.result.Usage = newJSONUsage(total)
+
+	// A cost is only meaningful when every response in the run was priced
+	switch {
+	case !priced:
+		p.result.Cost = nil
+	case !p.anyUsage:
+		p.result.Cost = &cost
+	case p.result.Cost != nil:
+		sum := *p.result.Cost + cost
+		p.result.Cost = &sum
+	}
+	p.anyUsage = true
+}
+
+// CommandDetected adds a command to the result
+func (p *jsonPresenter) CommandDetected(proposal types.CommandProposal) {
+	p.result.Commands = append(p.result.Commands, newJSONCommand(proposal))
+}
+
+// ExecFinished records the exit code of the command
+func (p *jsonPresenter) ExecFinished(command string, exitCode int, err error) {
+	p.stderrPresenter.ExecFinished(command, exitCode, err)
+	for i := range p.result.Commands {
+		if c := &p.result.Commands[i]; c.Command == command && !c.Executed {
+			c.Executed = true
+			c.ExitCode = &exitCode
+			return
+		}
+	}
+}
+
+// AgentDone records the summary of an agent run
+func (p *jsonPresenter) AgentDone(summary string, success bool) {
+	p.result.Summary = summary
+	p.result.Success = &success
+}
+
+// Finish prints the result, once
+func (p *jsonPresenter) Finish() {
+	if p.printed {
+		return
+	}
+	p.printed = true
+
+	p.result.Answer = p.answer.String()
+	if p.result.Commands == nil {
+		p.result.Commands = []jsonCommand{}
+	}
+	writeJSON(p.result)
+}
+
+// ndjsonEvent is one line of the ndjson output, only the fields of its type are set
+type ndjsonEvent struct {
+	Type string `json:"type"`
+
+	Text    string          `json:"text,omitempty"`
+	Level   string          `json:"level,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Kind    types.ErrorKind `json:"kind,omitempty"`
+
+	Provider  string     `json:"provider,omitempty"`
+	Model     string     `json:"model,omitempty"`
+	CreatedAt int64      `json:"created_at,omitempty"`
+	Usage     *jsonUsage `json:"usage,omitempty"`
+	Cost      *float64   `json:"cost,omitempty"`
+
+	Command     string `json:"command,omitempty"`
+	Description string `json:"description,omitempty"`
+	WorkingDir  string `json:"working_dir,omitempty"`
+	Risk        string `json:"risk,omitempty"`
+	NeedsSudo   bool   `json:"needs_sudo,omitempty"`
+	ExitCode    *int   `json:"exit_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+
+	Step     int    `json:"step,omitempty"`
+	MaxSteps int    `json:"max_steps,omitempty"`
+	Summary  string `json:"summary,omitempty"`
+	Success  *bool  `json:"success,omitempty"`
+}
+
+// Event types of the ndjson output
+const (
+	eventStatus          = "status"
+	eventError           = "error"
+	eventToken           = "token"
+	eventCached          = "cached"
+	eventUsage           = "usage"
+	eventCommandDetected = "command_detected"
+	eventExecStarted     = "exec_started"
+	eventExecFinished    = "exec_finished"
+	eventAgentStep       = "agent_step"
+	eventAgentDone       = "agent_done"
+	eventDone            = "done"
+)
+
+// ndjsonPresenter prints every step of answering a question as a line of JSON as it happens
+type ndjsonPresenter struct {
+	stderrPresenter
+}
+
+// Status emits a status event
+func (p *ndjsonPresenter) Status(message, statusType string) {
+	writeJSON(ndjsonEvent{Type: eventStatus, Level: statusType, Message: message})
+}
+
+// Error emits the error that ended the command
+func (p *ndjsonPresenter) Error(err error) {
+	writeJSON(ndjsonEvent{Type: eventError, Message: errorMessage(err), Kind: types.KindOf(err)})
+}
+
+// StartResponse emits nothing, tokens follow
+func (p *ndjsonPresenter) StartResponse() {}
+
+// ResponseText emits a token event for streamed text
+func (p *ndjsonPresenter) ResponseText(text string) {
+	if text != "" {
+		writeJSON(ndjsonEvent{Type: eventToken, Text: text})
+	}
+}
+
+// EndResponse emits nothing, the usage event follows
+func (p *ndjsonPresenter) EndResponse() {}
+
+// Cached emits where the cached response came from
+func (p *ndjsonPresenter) Cached(providerName, model string, createdAt time.Time) {
+	writeJSON(ndjsonEvent{Type: eventCached, Provider: providerName, Model: model, CreatedAt: createdAt.Unix()})
+}
+
+// Usage emits the model that answered and the tokens it used
+func (p *ndjsonPresenter) Usage(providerName, model string, u *types.Usage, cost float64, priced bool) {
+	event := ndjsonEvent{Type: eventUsage, Provider: providerName, Model: model}
+	if u != nil {
+		event.Usage = newJSONUsage(*u)
+		if priced {
+			event.Cost = &cost
+		}
+	}
+	writeJSON(event)
+}
+
+// CommandDetected emits a command found in the response
+func (p *ndjsonPresenter) CommandDetected(proposal types.CommandProposal) {
+	writeJSON(ndjsonEvent{
+		Type:        eventCommandDetected,
+		Command:     proposal.Command,
+		Description: proposal.Description,
+		WorkingDir:  proposal.WorkingDir,
+		Risk:        proposal.Risk,
+		NeedsSudo:   proposal.NeedsSudo,
+	})
+}
+
+// ExecStarted emits the command that is starting
+func (p *ndjsonPresenter) ExecStarted(command string) {
+	writeJSON(ndjsonEvent{Type: eventExecStarted, Command: command})
+}
+
+// ExecFinished emits how the command ended
+func (p *ndjsonPresenter) ExecFinished(command string, exitCode int, err error) {
+	event := ndjsonEvent{Type: eventExecFinished, Command: command, ExitCode: &exitCode}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	writeJSON(event)
+}
+
+// AgentStep emits which step of an agent run is starting
+func (p *ndjsonPresenter) AgentStep(step, maxSteps int) {
+	writeJSON(ndjsonEvent{Type: eventAgentStep, Step: step, MaxSteps: maxSteps})
+}
+
+// AgentDone emits the summary of an agent run
+func (p *ndjsonPresenter) AgentDone(summary string, success bool) {
+	writeJSON(ndjsonEvent{Type: eventAgentDone, Summary: summary, Success: &success})
+}
+
+// Finish emits the done event
+func (p *ndjsonPresenter) Finish() {
+	writeJSON(ndjsonEvent{Type: eventDone})
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/internal/provider"
+)
+
+// ShowModels displays a provider's models with their limits and capabilities
+func ShowModels(providerName string, models []provider.Model, fetchedAt time.Time, fresh bool) {
+	count := fmt.Sprintf("%d models", len(models))
+	if len(models) == 1 {
+		count = "1 model"
+	}
+	fmt.Println(lipgloss.NewStyle().Foreground(gold).Bold(true).Render(fmt.Sprintf("%s (%s)", providerName, count)))
+	if len(models) == 0 {
+		fmt.Println(QuestionStyle.Render("No models available"))
+		return
+	}
+
+	width := len("Model")
+	for _, m := range models {
+		width = max(width, len(m.Name))
+	}
+
+	row := "  %-*s  %8s  %8s  %s"
+	fmt.Println(lipgloss.NewStyle().Foreground(slate).Bold(true).Render(fmt.Sprintf(row, width, "Model", "Context", "Output", "Capabilities")))
+	for _, m := range models {
+		fmt.Println(lipgloss.NewStyle().Foreground(pearl).Render(fmt.Sprintf(row, width, m.Name,
+			formatTokenLimit(m.InputTokenLimit), formatTokenLimit(m.OutputTokenLimit), strings.Join(capabilities(m), ", "))))
+	}
+
+	if !fresh {
+		fmt.Println(lipgloss.NewStyle().Foreground(slate).Render(
+			fmt.Sprintf("  Fetched %s ago, use --refresh to fetch again", formatAge(time.Since(fetchedAt)))))
+	}
+	fmt.Println()
+}
+
+// ModelSummary describes a model's context window and capabilities in one line, e.g. for shell completion
+func ModelSummary(m provider.Model) string {
+	var parts []string
+	if m.InputTokenLimit > 0 {
+		parts = append(parts, formatTokenLimit(m.InputTokenLimit)+" context")
+	}
+	parts = append(parts, capabilities(m)...)
+	return strings.Join(parts, ", ")
+}
+
+// capabilities lists what a model can do beyond answering text
+func capabilities(m provider.Model) []string {
+	var list []string
+	if m.Vision {
+		list = append(list, "vision")
+	}
+	if m.Tools {
+		list = append(list, "tools")
+	}
+	if m.Thinking {
+		list = append(list, "thinking")
+	}
+	return list
+}
+
+// formatTokenLimit formats a token count compactly, e.g. "128K" or "1M", or "-" when unknown.
+// Limits are given in powers of two by some providers and of ten by others, both are shown round
+func formatTokenLimit(n int) string {
+	switch {
+	case n <= 0:
+		return "-"
+	case n%1_000_000 == 0:
+		return fmt.Sprintf("%dM", n/1_000_000)
+	case n%(1<<20) == 0:
+		return fmt.Sprintf("%dM", n>>20)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.3gM", float64(n)/1_000_000)
+	case n%1000 == 0:
+		return fmt.Sprintf("%dK", n/1000)
+	case n%(1<<10) == 0:
+		return fmt.Sprintf("%dK", n>>10)
+	case n >= 1000:
+		return fmt.Sprintf("%dK", n/1000)
+	default:
+		return fmt.Sprint(n)
+	}
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/attach"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// Output modes selectable with --output
+const (
+	OutputPretty = "pretty"
+	OutputRaw    = "raw"
+	OutputJSON   = "json"
+	OutputNDJSON = "ndjson"
+)
+
+// OutputModes lists the output modes in the order they are documented
+var OutputModes = []string{OutputPretty, OutputRaw, OutputJSON, OutputNDJSON}
+
+// Presenter shows the progress and result of answering a question, one implementation per output mode
+type Presenter interface {
+	// Status shows an info, success, warning, error or executing message
+	Status(message, statusType string)
+	// Error shows the error that ends the command
+	Error(err error)
+	// Attachments lists the context and images sent with a question
+	Attachments(attachments []*attach.Attachment, images []*attach.Image, dropped []string)
+
+	// StartResponse, ResponseText and EndResponse show a response as it streams
+	StartResponse()
+	ResponseText(text string)
+	EndResponse()
+	// Cached notes that the response came from the cache instead of the model
+	Cached(providerName, model string, createdAt time.Time)
+	// Usage shows the model that answered and the tokens it used, u is nil when the provider didn't say
+	Usage(providerName, model string, u *types.Usage, cost float64, priced bool)
+
+	// CommandDetected reports a command found in the response, whether or not it will run
+	CommandDetected(proposal types.CommandProposal)
+	// CommandProposal shows a command before asking to run it
+	CommandProposal(proposal types.CommandProposal)
+	// ExecStarted and ExecFinished report a confirmed command running
+	ExecStarted(command string)
+	ExecFinished(command string, exitCode int, err error)
+	// CommandOutput is where executed commands write their output
+	CommandOutput() io.Writer
+
+	// AgentStep and AgentDone show the progress of an agent run
+	AgentStep(step, maxSteps int)
+	AgentDone(summary string, success bool)
+
+	// Finish completes the output once the question has been handled
+	Finish()
+}
+
+// current is the presenter all output goes through, the terminal one unless SetOutput chose another
+var current Presenter = &prettyPresenter{}
+
+// SetOutput selects the presenter for an output mode
+func SetOutput(mode string) error {
+	switch mode {
+	case "", OutputPretty:
+		current = &prettyPresenter{}
+	case OutputRaw:
+		current = &rawPresenter{}
+	case OutputJSON:
+		current = &jsonPresenter{}
+	case OutputNDJSON:
+		current = &ndjsonPresenter{}
+	default:
+		return types.NewError(types.ErrorUsage, fmt.Errorf("unknown output mode %q (use %s, %s, %s or %s)", mode, OutputPretty, OutputRaw, OutputJSON, OutputNDJSON))
+	}
+	return nil
+}
+
+// FinishOutput completes the output of the selected mode, e.g. printing the JSON result
+func FinishOutput() {
+	current.Finish()
+}
+
+// CommandOutput returns where executed commands should write their output
+func CommandOutput() io.Writer {
+	return current.CommandOutput()
+}
+
+// promptOutput returns where prompts are drawn, keeping stdout machine-readable in the other modes
+func promptOutput() io.Writer {
+	if _, ok := current.(*prettyPresenter); ok {
+		return os.Stdout
+	}
+	return os.Stderr
+}
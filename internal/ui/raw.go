@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/attach"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// stderrPresenter writes everything but the response as plain text to stderr, so stdout only
+// carries what the output mode promises. The raw, json and ndjson presenters build on it
+type stderrPresenter struct{}
+
+// Status prints a status message to stderr
+func (p *stderrPresenter) Status(message, statusType string) {
+	fmt.Fprintln(os.Stderr, statusPrefix(statusType), message)
+}
+
+// Error prints an error to stderr
+func (p *stderrPresenter) Error(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", errorMessage(err))
+}
+
+// Attachments lists the attachments on stderr
+func (p *stderrPresenter) Attachments(attachments []*attach.Attachment, images []*attach.Image, dropped []string) {
+	fmt.Fprintln(os.Stderr, "Attached:")
+	for _, a := range attachments {
+		fmt.Fprintf(os.Stderr, "  %s (%s)\n", a.Name, attachmentDetail(a))
+	}
+	for _, image := range images {
+		fmt.Fprintf(os.Stderr, "  %s (%s)\n", image.Name, imageDetail(image))
+	}
+	for _, name := range dropped {
+		fmt.Fprintf(os.Stderr, "  %s (dropped to fit the token budget)\n", name)
+	}
+}
+
+// Cached notes a cache hit on stderr
+func (p *stderrPresenter) Cached(providerName, model string, createdAt time.Time) {
+	fmt.Fprintln(os.Stderr, cachedNotice(createdAt))
+}
+
+// Usage prints the usage footer to stderr
+func (p *stderrPresenter) Usage(providerName, model string, u *types.Usage, cost float64, priced bool) {
+	fmt.Fprintln(os.Stderr, usageLine(providerName, model, u, cost, priced))
+}
+
+// CommandDetected prints nothing, the command is part of the response
+func (p *stderrPresenter) CommandDetected(proposal types.CommandProposal) {}
+
+// CommandProposal describes a command on stderr before asking to run it
+func (p *stderrPresenter) CommandProposal(proposal types.CommandProposal) {
+	fmt.Fprintln(os.Stderr, "→", proposal.Command)
+
+	var details []string
+	if proposal.Description != "" {
+		details = append(details, proposal.Description)
+	}
+	if proposal.WorkingDir != "" {
+		details = append(details, "in "+proposal.WorkingDir)
+	}
+	if proposal.Risk == types.RiskHigh || proposal.Risk == types.RiskMedium {
+		details = append(details, proposal.Risk+" risk")
+	}
+	if proposal.NeedsSudo {
+		details = append(details, "needs sudo")
+	}
+	if len(details) > 0 {
+		fmt.Fprintln(os.Stderr, "  "+strings.Join(details, " · "))
+	}
+}
+
+// ExecStarted prints nothing, the command's own output follows
+func (p *stderrPresenter) ExecStarted(command string) {}
+
+// ExecFinished notes commands that failed on stderr
+func (p *stderrPresenter) ExecFinished(command string, exitCode int, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Command failed: %s\n", command)
+	}
+}
+
+// CommandOutput sends command output to stderr, keeping it apart from the response
+func (p *stderrPresenter) CommandOutput() io.Writer {
+	return os.Stderr
+}
+
+// AgentStep prints which step of an agent run is starting to stderr
+func (p *stderrPresenter) AgentStep(step, maxSteps int) {
+	fmt.Fprintf(os.Stderr, "Step %d/%d\n", step, maxSteps)
+}
+
+// rawPresenter prints the response as plain, unrendered text on stdout
+type rawPresenter struct {
+	stderrPresenter
+	// lastText is the end of the most recent output, to know whether it ended its line
+	lastText string
+}
+
+// StartResponse separates the response from an earlier one in the same run
+func (p *rawPresenter) StartResponse() {
+	if p.lastText != "" {
+		fmt.Println()
+	}
+}
+
+// ResponseText prints streamed text as it arrives
+func (p *rawPresenter) ResponseText(text string) {
+	fmt.Print(text)
+	if text != "" {
+		p.lastText = text
+	}
+}
+
+// EndResponse ends the response's last line
+func (p *rawPresenter) EndResponse() {
+	if p.lastText != "" && !strings.HasSuffix(p.lastText, "\n") {
+		fmt.Println()
+		p.lastText += "\n"
+	}
+}
+
+// AgentDone prints the summary of an agent run as the last part of the response
+func (p *rawPresenter) AgentDone(summary string, success bool) {
+	if !success {
+		fmt.Fprintln(os.Stderr, "Task not completed")
+	}
+	p.StartResponse()
+	p.ResponseText(summary)
+	p.EndResponse()
+}
+
+// Finish has nothing left to print, the response was printed as it arrived
+func (p *rawPresenter) Finish() {}
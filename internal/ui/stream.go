@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// MarkdownStream renders streamed markdown as it arrives. Completed blocks are
+// rendered once and committed to the terminal, while the unfinished tail is
+// re-rendered and redrawn on every update.
+type MarkdownStream struct {
+	renderer  *glamour.TermRenderer
+	live      bool
+	width     int
+	height    int
+	pending   string
+	tailLines int
+}
+
+// NewMarkdownStream creates a stream that renders live when stdout is a terminal
+func NewMarkdownStream() *MarkdownStream {
+	s := &MarkdownStream{renderer: markdownRenderer}
+
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) || markdownRenderer == nil {
+		return s
+	}
+
+	width, height, err := term.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		return s
+	}
+
+	// Narrow terminals need a narrower wrap so redrawn lines don't soft-wrap
+	if width-4 < 80 {
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(max(width-4, 20)),
+		)
+		if err == nil {
+			s.renderer = renderer
+		}
+	}
+
+	s.live = true
+	s.width = width
+	s.height = height
+	return s
+}
+
+// Write adds streamed text and updates the terminal
+func (s *MarkdownStream) Write(text string) {
+	if !s.live {
+		StreamMarkdownText(text)
+		return
+	}
+
+	s.pending += text
+	s.clearTail()
+
+	if idx := stableBoundary(s.pending); idx > 0 {
+		s.commit(s.pending[:idx])
+		s.pending = s.pending[idx:]
+	}
+
+	tail := s.render(s.pending)
+	// A tail taller than the screen can't be erased, so commit its complete lines early
+	if visualLines(tail, s.width) >= s.height-1 {
+		s.forceCommit()
+		tail = s.render(s.pending)
+	}
+	s.drawTail(tail)
+}
+
+// Close commits whatever is left of the response
+func (s *MarkdownStream) Close() {
+	if !s.live {
+		fmt.Println()
+		return
+	}
+
+	s.clearTail()
+	if strings.TrimSpace(s.pending) != "" {
+		s.commit(s.pending)
+	}
+	s.pending = ""
+}
+
+// commit renders a finished block and prints it permanently
+func (s *MarkdownStream) commit(block string) {
+	if rendered := s.render(block); rendered != "" {
+		fmt.Print(rendered + "\n\n")
+	}
+}
+
+// forceCommit commits all complete lines of the tail, splitting an open code block if needed
+func (s *MarkdownStream) forceCommit() {
+	idx := strings.LastIndex(s.pending, "\n")
+	if idx <= 0 {
+		return
+	}
+
+	head, rest := s.pending[:idx+1], s.pending[idx+1:]
+	if opener, open := openFence(head); open {
+		head += fenceMarker(opener) + "\n"
+		rest = opener + "\n" + rest
+	}
+
+	s.commit(head)
+	s.pending = rest
+}
+
+// drawTail prints the rendered tail and remembers how many lines it took
+func (s *MarkdownStream) drawTail(tail string) {
+	if tail == "" {
+		s.tailLines = 0
+		return
+	}
+	fmt.Print(tail + "\n")
+	s.tailLines = visualLines(tail, s.width)
+}
+
+// clearTail erases the previously drawn tail
+func (s *MarkdownStream) clearTail() {
+	if s.tailLines > 0 {
+		// Move to the start of the first tail line and clear to the end of the screen
+		fmt.Printf("\x1b[%dF\x1b[J", s.tailLines)
+		s.tailLines = 0
+	}
+}
+
+// render renders markdown and strips the surrounding blank lines glamour adds
+func (s *MarkdownStream) render(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	rendered, err := s.renderer.Render(text)
+	if err != nil {
+		rendered = text
+	}
+	return trimBlankLines(rendered)
+}
+
+// stableBoundary returns the offset just past the last blank line outside a code block, or 0
+func stableBoundary(text string) int {
+	boundary := 0
+	inFence := false
+	marker := ""
+	offset := 0
+
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		offset += len(line)
+		// The last element has no newline yet, so it is still being written
+		if i == len(lines)-1 && !strings.HasSuffix(line, "\n") {
+			break
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inFence && isFence(trimmed):
+			inFence = true
+			marker = fenceMarker(trimmed)
+		case inFence && strings.HasPrefix(trimmed, marker) && strings.Trim(trimmed, marker[:1]) == "":
+			inFence = false
+		case !inFence && trimmed == "":
+			boundary = offset
+		}
+	}
+
+	return boundary
+}
+
+// openFence reports whether text ends inside a code block and returns its opening line
+func openFence(text string) (string, bool) {
+	opener := ""
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case opener == "" && isFence(trimmed):
+			opener = trimmed
+		case opener != "" && strings.HasPrefix(trimmed, fenceMarker(opener)) && strings.Trim(trimmed, opener[:1]) == "":
+			opener = ""
+		}
+	}
+	return opener, opener != ""
+}
+
+// isFence reports whether a trimmed line opens or closes a fenced code block
+func isFence(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+// fenceMarker returns the run of backticks or tildes that starts a fence line
+func fenceMarker(line string) string {
+	if line == "" {
+		return ""
+	}
+	end := strings.IndexFunc(line, func(r rune) bool { return r != rune(line[0]) })
+	if end == -1 {
+		return line
+	}
+	return line[:end]
+}
+
+// trimBlankLines removes leading and trailing lines that contain only whitespace
+func trimBlankLines(text string) string {
+	lines := strings.Split(text, "\n")
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return strings.Join(lines[start:end], "\n")
+}
+
+// visualLines counts the terminal rows text occupies, accounting for soft wrapping
+func visualLines(text string, width int) int {
+	if text == "" {
+		return 0
+	}
+	rows := 0
+	for _, line := range strings.Split(text, "\n") {
+		w := lipgloss.Width(line)
+		if width <= 0 || w <= width {
+			rows++
+			continue
+		}
+		rows += (w + width - 1) / width
+	}
+	return rows
+}
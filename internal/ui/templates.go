@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/internal/templates"
+)
+
+// PromptForTemplateVars asks for the values of template variables using a huh form
+func PromptForTemplateVars(templateName string, vars []templates.Variable) (map[string]string, error) {
+	values := make([]string, len(vars))
+	fields := make([]huh.Field, len(vars))
+	for i, v := range vars {
+		fields[i] = huh.NewInput().
+			Title(v.Name).
+			Description(v.Description).
+			Value(&values[i])
+	}
+
+	form := huh.NewForm(huh.NewGroup(fields...).Title("Template " + templateName)).WithOutput(promptOutput())
+	if err := form.Run(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(vars))
+	for i, v := range vars {
+		result[v.Name] = strings.TrimSpace(values[i])
+	}
+	return result, nil
+}
+
+// ShowTemplateList displays the available prompt templates
+func ShowTemplateList(dir string, list []*templates.Template) {
+	if len(list) == 0 {
+		ShowExecutionStatus(fmt.Sprintf("No templates yet, add *%s files to %s", templates.Extension, dir), "info")
+		return
+	}
+
+	nameStyle := lipgloss.NewStyle().Foreground(yellow).Bold(true)
+	varStyle := lipgloss.NewStyle().Foreground(slate)
+
+	for _, t := range list {
+		fmt.Printf("%s  %s\n", nameStyle.Render(t.Name), t.Description)
+		if len(t.Vars) > 0 {
+			fmt.Println("  " + varStyle.Render("vars: "+formatVars(t.Vars)))
+		}
+	}
+	fmt.Println(varStyle.Render("\nTemplates directory: " + dir))
+}
+
+// ShowTemplate displays a template's description, variables and source
+func ShowTemplate(t *templates.Template, source string) {
+	fmt.Println(HeaderStyle.Render(t.Name))
+	if t.Description != "" {
+		fmt.Println(t.Description)
+	}
+	for _, v := range t.Vars {
+		detail := v.Description
+		if v.Default != "" {
+			detail += fmt.Sprintf(" (default: %s)", v.Default)
+		}
+		fmt.Printf("  %s %s\n", lipgloss.NewStyle().Foreground(yellow).Render(v.Name), lipgloss.NewStyle().Foreground(slate).Render(detail))
+	}
+	fmt.Println(lipgloss.NewStyle().Foreground(slate).Render("\n" + t.Path))
+	fmt.Println()
+	fmt.Println(source)
+}
+
+// formatVars lists variables, marking the ones with defaults
+func formatVars(vars []templates.Variable) string {
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+		if v.Default != "" {
+			names[i] += "=" + v.Default
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simplyzetax/oracle/internal/usage"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// ShowUsage displays a one-line footer with the model that answered, the tokens it used and
+// its estimated cost, or only the model when the provider didn't report usage
+func ShowUsage(providerName, model string, u *types.Usage, cost float64, priced bool) {
+	current.Usage(providerName, model, u, cost, priced)
+}
+
+// Usage displays the footer with the model that answered and the tokens it used
+func (p *prettyPresenter) Usage(providerName, model string, u *types.Usage, cost float64, priced bool) {
+	fmt.Println(lipgloss.NewStyle().Foreground(slate).Render(usageLine(providerName, model, u, cost, priced)))
+}
+
+// usageLine describes the model that answered, the tokens it used and their estimated cost
+func usageLine(providerName, model string, u *types.Usage, cost float64, priced bool) string {
+	line := providerName + "/" + model
+	if u != nil {
+		parts := []string{
+			fmt.Sprintf("%d prompt", u.PromptTokens),
+			fmt.Sprintf("%d response", u.ResponseTokens),
+		}
+		if u.ThinkingTokens > 0 {
+			parts = append(parts, fmt.Sprintf("%d thinking", u.ThinkingTokens))
+		}
+
+		line += " · Tokens: " + strings.Join(parts, " · ")
+		if priced {
+			line += " · " + formatCost(cost)
+		}
+	}
+	return line
+}
+
+// ShowUsageReport displays a table of usage grouped by day, model or persona
+func ShowUsageReport(by string, summaries []usage.Summary) {
+	if len(summaries) == 0 {
+		fmt.Println(QuestionStyle.Render("No usage recorded yet"))
+		return
+	}
+
+	headerStyle := lipgloss.NewStyle().Foreground(gold).Bold(true)
+	keyStyle := lipgloss.NewStyle().Foreground(pearl)
+	metaStyle := lipgloss.NewStyle().Foreground(slate)
+
+	width := len(by)
+	for _, s := range summaries {
+		width = max(width, len(s.Key))
+	}
+
+	row := "%-*s  %8s  %12s  %12s  %12s  %10s"
+	fmt.Println(headerStyle.Render(fmt.Sprintf(row, width, strings.ToUpper(by[:1])+by[1:], "Requests", "Prompt", "Response", "Thinking", "Cost")))
+
+	var total usage.Summary
+	for _, s := range summaries {
+		fmt.Println(keyStyle.Render(fmt.Sprintf(row, width, s.Key, fmt.Sprint(s.Requests),
+			fmt.Sprint(s.Usage.PromptTokens), fmt.Sprint(s.Usage.ResponseTokens), fmt.Sprint(s.Usage.ThinkingTokens), summaryCost(s))))
+
+		total.Requests += s.Requests
+		total.Usage.PromptTokens += s.Usage.PromptTokens
+		total.Usage.ResponseTokens += s.Usage.ResponseTokens
+		total.Usage.ThinkingTokens += s.Usage.ThinkingTokens
+		total.Cost += s.Cost
+		total.Unpriced += s.Unpriced
+	}
+
+	fmt.Println(headerStyle.Render(fmt.Sprintf(row, width, "Total", fmt.Sprint(total.Requests),
+		fmt.Sprint(total.Usage.PromptTokens), fmt.Sprint(total.Usage.ResponseTokens), fmt.Sprint(total.Usage.ThinkingTokens), summaryCost(total))))
+
+	if total.Unpriced > 0 {
+		fmt.Println(metaStyle.Render(fmt.Sprintf("* %d requests use models without a known price and aren't included in the cost, add them to Prices in the config", total.Unpriced)))
+	}
+}
+
+// ShowBudgetStatus displays how much of the daily and monthly token budgets is used
+func ShowBudgetStatus(budget types.UsageBudget, today, month int) {
+	var lines []string
+	if budget.DailyTokens > 0 {
+		lines = append(lines, fmt.Sprintf("Today: %d of %d tokens (%d%%)", today, budget.DailyTokens, today*100/budget.DailyTokens))
+	}
+	if budget.MonthlyTokens > 0 {
+		lines = append(lines, fmt.Sprintf("This month: %d of %d tokens (%d%%)", month, budget.MonthlyTokens, month*100/budget.MonthlyTokens))
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(lipgloss.NewStyle().Foreground(slate).Render(fmt.Sprintf("Budget (%s when used up)", budget.Action)))
+	for _, line := range lines {
+		fmt.Println(QuestionStyle.Render(line))
+	}
+}
+
+// summaryCost formats a summary's estimated cost, marking it when some requests couldn't be priced
+func summaryCost(s usage.Summary) string {
+	if s.Unpriced == s.Requests {
+		return "-"
+	}
+	cost := formatCost(s.Cost)
+	if s.Unpriced > 0 {
+		cost += "*"
+	}
+	return cost
+}
+
+// formatCost formats an estimated cost in dollars, with more precision for small amounts
+func formatCost(cost float64) string {
+	if cost < 0.01 {
+		return fmt.Sprintf("~$%.4f", cost)
+	}
+	return fmt.Sprintf("~$%.2f", cost)
+}
@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/simplyzetax/oracle/internal/config"
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// GetLedgerPath returns the path of the usage ledger, one JSON record per line
+func GetLedgerPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "usage.jsonl"), nil
+}
+
+// Record appends a request's token usage to the ledger
+func Record(record types.UsageRecord) error {
+	path, err := GetLedgerPath()
+	if err != nil {
+		return fmt.Errorf("failed to get usage ledger path: %w", err)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage record: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage record: %w", err)
+	}
+	return nil
+}
+
+// Load returns the records in the ledger made at or after since, oldest first
+func Load(since time.Time) ([]types.UsageRecord, error) {
+	path, err := GetLedgerPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage ledger path: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	var records []types.UsageRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record types.UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// Skip damaged lines, e.g. from an interrupted write, instead of losing the rest
+			continue
+		}
+		if record.Timestamp >= since.Unix() {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	return records, nil
+}
+
+// StartOfDay returns midnight of t's day in its location
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfMonth returns midnight of the first day of t's month in its location
+func StartOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
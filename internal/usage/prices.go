@@ -0,0 +1,50 @@
+package usage
+
+import (
+	"strings"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// defaultPrices are list prices in dollars per million tokens, used for estimates only.
+// Entries match models whose name starts with the key, the longest key wins
+var defaultPrices = map[string]types.Price{
+	"gemini-1.5-flash": {Input: 0.075, Output: 0.30},
+	"gemini-1.5-pro":   {Input: 1.25, Output: 5.00},
+	"gemini-2.0-flash": {Input: 0.10, Output: 0.40},
+	"gemini-2.5-flash": {Input: 0.30, Output: 2.50},
+	"gemini-2.5-pro":   {Input: 1.25, Output: 10.00},
+	"gpt-4o":           {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":      {Input: 0.15, Output: 0.60},
+	"gpt-4.1":          {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":     {Input: 0.40, Output: 1.60},
+}
+
+// PriceFor returns the price of a model from the config's table or the built-in one
+func PriceFor(model string, prices map[string]types.Price) (types.Price, bool) {
+	model = strings.TrimPrefix(model, "models/")
+	if price, ok := lookupPrice(model, prices); ok {
+		return price, true
+	}
+	return lookupPrice(model, defaultPrices)
+}
+
+// lookupPrice finds the entry whose key is the longest prefix of model
+func lookupPrice(model string, prices map[string]types.Price) (types.Price, bool) {
+	var best string
+	for key := range prices {
+		if strings.HasPrefix(model, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return types.Price{}, false
+	}
+	return prices[best], true
+}
+
+// Cost estimates what usage costs in dollars, thinking tokens are billed as output
+func Cost(u types.Usage, price types.Price) float64 {
+	output := u.ResponseTokens + u.ThinkingTokens
+	return (float64(u.PromptTokens)*price.Input + float64(output)*price.Output) / 1_000_000
+}
@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/simplyzetax/oracle/pkg/types"
+)
+
+// Report groupings
+const (
+	ByDay     = "day"
+	ByModel   = "model"
+	ByPersona = "persona"
+)
+
+// Summary is the usage of one group of requests in a report
+type Summary struct {
+	Key      string
+	Requests int
+	Usage    types.Usage
+	Cost     float64
+	// Unpriced counts the requests whose model has no known price and aren't in Cost
+	Unpriced int
+}
+
+// Summarize groups records by day, model or persona and totals their usage and estimated cost
+func Summarize(records []types.UsageRecord, by string, prices map[string]types.Price) ([]Summary, error) {
+	var keyOf func(types.UsageRecord) string
+	switch by {
+	case ByDay:
+		keyOf = func(r types.UsageRecord) string { return time.Unix(r.Timestamp, 0).Format("2006-01-02") }
+	case ByModel:
+		keyOf = func(r types.UsageRecord) string { return r.Provider + "/" + r.Model }
+	case ByPersona:
+		keyOf = func(r types.UsageRecord) string {
+			if r.Persona == "" {
+				return "(none)"
+			}
+			return r.Persona
+		}
+	default:
+		return nil, fmt.Errorf("unknown grouping %q (use %s, %s or %s)", by, ByDay, ByModel, ByPersona)
+	}
+
+	groups := make(map[string]*Summary)
+	for _, record := range records {
+		key := keyOf(record)
+		summary, ok := groups[key]
+		if !ok {
+			summary = &Summary{Key: key}
+			groups[key] = summary
+		}
+
+		summary.Requests++
+		summary.Usage.PromptTokens += record.PromptTokens
+		summary.Usage.ResponseTokens += record.ResponseTokens
+		summary.Usage.ThinkingTokens += record.ThinkingTokens
+
+		if price, ok := PriceFor(record.Model, prices); ok {
+			summary.Cost += Cost(record.Usage, price)
+		} else {
+			summary.Unpriced++
+		}
+	}
+
+	summaries := make([]Summary, 0, len(groups))
+	for _, summary := range groups {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Key < summaries[j].Key
+	})
+	return summaries, nil
+}
+
+// TokensSince totals the tokens used by the records made at or after since
+func TokensSince(records []types.UsageRecord, since time.Time) int {
+	total := 0
+	for _, record := range records {
+		if record.Timestamp >= since.Unix() {
+			total += record.Total()
+		}
+	}
+	return total
+}
+
+// Exceeded describes the first token budget that is used up at now, or returns "" when none is
+func Exceeded(budget types.UsageBudget, records []types.UsageRecord, now time.Time) string {
+	if budget.DailyTokens > 0 {
+		if used := TokensSince(records, StartOfDay(now)); used >= budget.DailyTokens {
+			return fmt.Sprintf("the daily budget of %d tokens is used up (%d tokens today)", budget.DailyTokens, used)
+		}
+	}
+	if budget.MonthlyTokens > 0 {
+		if used := TokensSince(records, StartOfMonth(now)); used >= budget.MonthlyTokens {
+			return fmt.Sprintf("the monthly budget of %d tokens is used up (%d tokens this month)", budget.MonthlyTokens, used)
+		}
+	}
+	return ""
+}
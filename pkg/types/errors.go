@@ -0,0 +1,51 @@
+package types
+
+import "errors"
+
+// ErrorKind classifies a failure, so the command line can tell scripts what went wrong
+type ErrorKind string
+
+// Error kinds, each mapped to its own exit code
+const (
+	ErrorGeneral       ErrorKind = "error"
+	ErrorUsage         ErrorKind = "usage"
+	ErrorAuth          ErrorKind = "auth"
+	ErrorQuota         ErrorKind = "quota"
+	ErrorNetwork       ErrorKind = "network"
+	ErrorSafety        ErrorKind = "safety"
+	ErrorCancelled     ErrorKind = "cancelled"
+	ErrorCommandFailed ErrorKind = "command_failed"
+)
+
+// Error is a failure of a known kind
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError marks err as a failure of the given kind, nil stays nil
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf returns the kind of the outermost typed error in err's chain, or ErrorGeneral
+func KindOf(err error) ErrorKind {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Kind
+	}
+	return ErrorGeneral
+}
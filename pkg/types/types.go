@@ -2,8 +2,107 @@ package types
 
 // Config holds the application configuration
 type Config struct {
-	APIKey string
-	Model  string
+	APIKey    string
+	Model     string
+	Provider  string
+	Providers map[string]ProviderConfig
+
+	// Network settings shared by all providers
+	Proxy    string
+	CABundle string
+
+	// TokenBudget caps the tokens used by a question and its attached files
+	TokenBudget int
+
+	// ContextProbes selects which environment details are added to the system prompt,
+	// all of them when empty, and DisableContext turns the environment context off
+	ContextProbes  []string
+	DisableContext bool
+
+	// SystemPromptFile replaces the built-in base system prompt with the file's contents
+	SystemPromptFile string
+
+	// Generation holds default sampling parameters for every question
+	Generation GenerationConfig
+
+	// Prices overrides the built-in price table used to estimate costs, keyed by model name
+	Prices map[string]Price
+
+	// Budget limits the tokens used per day and month
+	Budget UsageBudget
+
+	// Cache controls the on-disk response cache
+	Cache CacheConfig
+
+	// Fallbacks are tried in order when the model is rate limited, unknown or down, each either
+	// a model of the same provider or "provider/model"
+	Fallbacks []string
+
+	// TemplatesDir holds prompt templates, e.g. a directory shared through a team repository
+	TemplatesDir string
+
+	// CassetteDir holds responses recorded with --record for the replay provider
+	CassetteDir string
+
+	// Persona is used when no --persona flag is given
+	Persona  string
+	Personas map[string]Persona
+}
+
+// GenerationConfig holds sampling parameters, unset fields leave the provider's default
+type GenerationConfig struct {
+	Temperature     *float32
+	TopP            *float32
+	TopK            *int
+	MaxOutputTokens *int
+	StopSequences   []string
+	Seed            *int
+	CandidateCount  *int
+}
+
+// Merge returns c with every field that is set in other replaced by other's value
+func (c GenerationConfig) Merge(other GenerationConfig) GenerationConfig {
+	if other.Temperature != nil {
+		c.Temperature = other.Temperature
+	}
+	if other.TopP != nil {
+		c.TopP = other.TopP
+	}
+	if other.TopK != nil {
+		c.TopK = other.TopK
+	}
+	if other.MaxOutputTokens != nil {
+		c.MaxOutputTokens = other.MaxOutputTokens
+	}
+	if other.StopSequences != nil {
+		c.StopSequences = other.StopSequences
+	}
+	if other.Seed != nil {
+		c.Seed = other.Seed
+	}
+	if other.CandidateCount != nil {
+		c.CandidateCount = other.CandidateCount
+	}
+	return c
+}
+
+// Persona is a named preset for the system prompt, model and answer style
+type Persona struct {
+	SystemPrompt string
+	Model        string
+	Temperature  *float32
+	Verbosity    string
+}
+
+// ProviderConfig holds the settings for a single model provider
+type ProviderConfig struct {
+	APIKey  string
+	BaseURL string
+
+	// Vertex AI settings, only used by the gemini provider
+	Backend  string
+	Project  string
+	Location string
 }
 
 // Question represents a user question
@@ -11,6 +110,14 @@ type Question struct {
 	Text      string
 	Model     string
 	Timestamp int64
+	// Attachments lists the context sent with the question, whose content isn't kept
+	Attachments []AttachmentRecord `json:",omitempty"`
+}
+
+// AttachmentRecord records a file, piped input or image attached to a question by name and size
+type AttachmentRecord struct {
+	Name  string
+	Bytes int
 }
 
 // Response represents an AI response
@@ -18,5 +125,88 @@ type Response struct {
 	Text      string
 	Model     string
 	Timestamp int64
-	Error     error
+	Error     error `json:"-"`
+}
+
+// Risk levels a command proposal can be assigned
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// CommandProposal is a shell command the model suggests running
+type CommandProposal struct {
+	Command     string
+	Description string
+	WorkingDir  string
+	Risk        string
+	NeedsSudo   bool
+}
+
+// CommandRecord records a command suggested in a response and whether it was run
+type CommandRecord struct {
+	Command  string
+	Executed bool
+	ExitCode int
+}
+
+// HistoryEntry is a persisted question and answer exchange
+type HistoryEntry struct {
+	ID        string
+	SessionID string
+	Provider  string
+	Question  Question
+	Response  Response
+	Commands  []CommandRecord
+}
+
+// CacheConfig controls the response cache, zero values use the defaults
+type CacheConfig struct {
+	Disabled bool
+	// TTL is how long a response is reused, as a Go duration like "24h"
+	TTL string
+	// MaxSizeMB caps the size of the cache directory
+	MaxSizeMB int
+}
+
+// Usage counts the tokens used by a single request
+type Usage struct {
+	PromptTokens   int
+	ResponseTokens int
+	ThinkingTokens int
+}
+
+// Total returns the number of tokens used, including thinking tokens
+func (u Usage) Total() int {
+	return u.PromptTokens + u.ResponseTokens + u.ThinkingTokens
+}
+
+// UsageRecord is a request's token usage as recorded in the usage ledger
+type UsageRecord struct {
+	Timestamp int64
+	Provider  string
+	Model     string
+	Persona   string
+	Usage
+}
+
+// Price is what a model's tokens cost, in dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Budget actions taken when a token budget is used up
+const (
+	BudgetBlock = "block"
+	BudgetWarn  = "warn"
+)
+
+// UsageBudget caps the tokens used per day and per month, zero means no limit
+type UsageBudget struct {
+	DailyTokens   int
+	MonthlyTokens int
+	// Action is BudgetBlock (the default) or BudgetWarn
+	Action string
 }